@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
 	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/middleware"
 	"github.com/your-org/go-next-template/internal/routes"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
 )
 
 func main() {
@@ -29,6 +33,24 @@ func main() {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	// Rewrite role permissions stored in the pre-grammar format and upgrade the admin role
+	if err := services.NewRoleService().UpgradeLegacyRoles(); err != nil {
+		log.Fatal("Failed to upgrade roles:", err)
+	}
+
+	// Load JWT signing keys
+	if err := utils.InitJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
+	// Start background email delivery
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	services.NewEmailWorker(services.NewMailer()).Start(ctx)
+
+	// Purge expired passkey ceremonies and external login states
+	services.NewCleanupWorker().Start(ctx)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -56,6 +78,7 @@ func main() {
 		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
 		AllowCredentials: true,
 	}))
+	app.Use("/api", middleware.RateLimit())
 
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
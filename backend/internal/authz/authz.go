@@ -0,0 +1,245 @@
+// Package authz computes a user's effective roles and permissions.
+//
+// A user holds a primary role (User.RoleID) plus any number of additional roles
+// (user_roles). Each role inherits the permissions of its parent chain. The effective
+// permission set is the union of every rule of every such role, evaluated with the
+// rules of package permissions (deny wins). Inactive roles contribute nothing and do
+// not pass on their parents' permissions.
+package authz
+
+import (
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/gorm"
+)
+
+// MaxDepth bounds how far role inheritance is followed
+const MaxDepth = 10
+
+// Subject is the effective authorization of a user
+type Subject struct {
+	UserID      uuid.UUID
+	Roles       []models.Role // Assigned and inherited roles, each once
+	Permissions permissions.Set
+}
+
+// Load computes the effective roles and permissions of a user
+func Load(db *gorm.DB, user *models.User) (*Subject, error) {
+	roleIDs, err := assignedRoleIDs(db, user)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := &Subject{UserID: user.ID}
+	if len(roleIDs) == 0 {
+		return subject, nil
+	}
+
+	roles, err := loadRoles(db)
+	if err != nil {
+		return nil, err
+	}
+
+	subject.Roles = Resolve(roles, roleIDs)
+	subject.Permissions = Merge(subject.Roles)
+	return subject, nil
+}
+
+// Can reports whether the subject may perform the action on the resource
+func (s *Subject) Can(resource, action string) bool {
+	return s.Permissions.Allows(resource, action)
+}
+
+// HasRole reports whether the subject holds the role, directly or by inheritance
+func (s *Subject) HasRole(name string) bool {
+	for _, role := range s.Roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRoles reports whether the subject holds any active role
+func (s *Subject) HasRoles() bool {
+	return len(s.Roles) > 0
+}
+
+// IsAdmin reports whether the subject has unrestricted access
+func (s *Subject) IsAdmin() bool {
+	return IsAdminSet(s.Permissions)
+}
+
+// RoleNames lists the names of the subject's effective roles
+func (s *Subject) RoleNames() []string {
+	names := make([]string, 0, len(s.Roles))
+	for _, role := range s.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// IsAdminSet reports whether a permission set grants everything ("*") without any deny rule
+func IsAdminSet(set permissions.Set) bool {
+	return set.Unrestricted()
+}
+
+// Resolve expands role IDs into the active roles they name plus their active ancestors.
+// Each role appears once; cycles and chains deeper than MaxDepth are cut off.
+func Resolve(roles map[uuid.UUID]models.Role, roleIDs []uuid.UUID) []models.Role {
+	var result []models.Role
+	seen := make(map[uuid.UUID]bool)
+
+	for _, id := range roleIDs {
+		current := &id
+		for depth := 0; current != nil && depth < MaxDepth; depth++ {
+			if seen[*current] {
+				break
+			}
+			role, ok := roles[*current]
+			if !ok || !role.IsActive {
+				break
+			}
+			seen[role.ID] = true
+			result = append(result, role)
+			current = role.ParentID
+		}
+	}
+	return result
+}
+
+// Merge combines the permission documents of several roles into one set.
+// Invalid entries are rejected when roles are saved; any left over are ignored here.
+func Merge(roles []models.Role) permissions.Set {
+	var set permissions.Set
+	for _, role := range roles {
+		rules, _ := permissions.ParseDocument(role.Permissions)
+		set = append(set, rules...)
+	}
+	return set
+}
+
+// CountAdmins counts active users whose merged role set is unrestricted, judged the
+// same way as Subject.IsAdmin: a deny rule in any of a user's roles removes them.
+func CountAdmins(db *gorm.DB) (int64, error) {
+	ids, err := AdminUserIDs(db)
+	return int64(len(ids)), err
+}
+
+// AdminUserIDs returns the active users whose merged role set is unrestricted
+func AdminUserIDs(db *gorm.DB) ([]uuid.UUID, error) {
+	roles, err := loadRoles(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only users holding a role that reaches an allow-everything rule can be admins
+	var candidates []uuid.UUID
+	for id := range roles {
+		if grantsAll(Merge(Resolve(roles, []uuid.UUID{id}))) {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var users []models.User
+	if err := db.Select("id", "role_id").
+		Where("is_active = ?", true).
+		Where("role_id IN ? OR id IN (?)", candidates,
+			db.Model(&models.UserRole{}).Select("user_id").Where("role_id IN ?", candidates)).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+	var links []models.UserRole
+	if err := db.Where("user_id IN ?", userIDs).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	additional := make(map[uuid.UUID][]uuid.UUID, len(users))
+	for _, link := range links {
+		additional[link.UserID] = append(additional[link.UserID], link.RoleID)
+	}
+
+	var admins []uuid.UUID
+	for _, user := range users {
+		var roleIDs []uuid.UUID
+		if user.RoleID != nil {
+			roleIDs = append(roleIDs, *user.RoleID)
+		}
+		roleIDs = append(roleIDs, additional[user.ID]...)
+		if IsAdminSet(Merge(Resolve(roles, roleIDs))) {
+			admins = append(admins, user.ID)
+		}
+	}
+	return admins, nil
+}
+
+// grantsAll reports whether the set has an allow-everything rule, ignoring deny rules
+func grantsAll(set permissions.Set) bool {
+	for _, rule := range set {
+		if !rule.Deny && rule.Resource == permissions.Wildcard && rule.Action == permissions.Wildcard && rule.Scope == permissions.ScopeAny {
+			return true
+		}
+	}
+	return false
+}
+
+// ParentCycle reports whether making parentID the parent of roleID would create a cycle
+func ParentCycle(db *gorm.DB, roleID, parentID uuid.UUID) (bool, error) {
+	roles, err := loadRoles(db)
+	if err != nil {
+		return false, err
+	}
+
+	current := &parentID
+	for depth := 0; current != nil; depth++ {
+		if *current == roleID || depth >= MaxDepth {
+			return true, nil
+		}
+		role, ok := roles[*current]
+		if !ok {
+			return false, nil
+		}
+		current = role.ParentID
+	}
+	return false, nil
+}
+
+// assignedRoleIDs returns the primary role followed by the user's additional roles
+func assignedRoleIDs(db *gorm.DB, user *models.User) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if user.RoleID != nil {
+		ids = append(ids, *user.RoleID)
+	}
+
+	var additional []uuid.UUID
+	if err := db.Model(&models.UserRole{}).Where("user_id = ?", user.ID).Pluck("role_id", &additional).Error; err != nil {
+		return nil, err
+	}
+	return append(ids, additional...), nil
+}
+
+// loadRoles loads every role keyed by ID. Role tables are small, so inheritance
+// is resolved in memory instead of with recursive queries.
+func loadRoles(db *gorm.DB) (map[uuid.UUID]models.Role, error) {
+	var list []models.Role
+	if err := db.Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make(map[uuid.UUID]models.Role, len(list))
+	for _, role := range list {
+		roles[role.ID] = role
+	}
+	return roles, nil
+}
@@ -0,0 +1,231 @@
+package authz
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// Snapshot is a cached copy of a user with their primary role and effective permissions.
+// Treat User and Subject as read-only.
+//
+// It also remembers which of the user's sessions and impersonations were found live, so
+// requests skip those lookups too. Revoking either must invalidate the user's snapshot
+// once the revocation has committed.
+type Snapshot struct {
+	User    models.User
+	Subject *Subject
+
+	mu             sync.Mutex
+	sessions       map[uuid.UUID]bool
+	impersonations map[uuid.UUID]*models.Impersonation
+}
+
+// SessionActive reports whether the session was remembered as live
+func (s *Snapshot) SessionActive(sessionID uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[sessionID]
+}
+
+// RememberSession records a session found live for the snapshot's lifetime
+func (s *Snapshot) RememberSession(sessionID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[uuid.UUID]bool)
+	}
+	s.sessions[sessionID] = true
+}
+
+// Impersonation returns a remembered impersonation of the user. Callers must still
+// check that it has not expired, and must not modify it.
+func (s *Snapshot) Impersonation(id uuid.UUID) (*models.Impersonation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	impersonation, ok := s.impersonations[id]
+	return impersonation, ok
+}
+
+// RememberImpersonation records an impersonation of the user found running
+func (s *Snapshot) RememberImpersonation(impersonation *models.Impersonation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.impersonations == nil {
+		s.impersonations = make(map[uuid.UUID]*models.Impersonation)
+	}
+	s.impersonations[impersonation.ID] = impersonation
+}
+
+// CacheStats reports how well the cache is doing
+type CacheStats struct {
+	Enabled       bool    `json:"enabled"`
+	TTL           string  `json:"ttl"`
+	Entries       int     `json:"entries"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRate       float64 `json:"hit_rate"` // hits / (hits + misses), 0 before the first lookup
+	Invalidations uint64  `json:"invalidations"`
+}
+
+type cacheEntry struct {
+	snapshot  *Snapshot
+	expiresAt time.Time
+}
+
+// Cache serves user snapshots for authentication so requests skip the database.
+// Snapshots live in process memory; with several instances, a change made through one
+// reaches the others within the TTL.
+//
+// Invalidate only after the change has committed. A load that started before the
+// invalidation may have read the old row, so it is returned but not cached; one that
+// starts after it reads the committed row.
+type Cache struct {
+	ttl        time.Duration // 0 disables caching
+	maxEntries int
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+
+	mu         sync.Mutex
+	entries    map[uuid.UUID]cacheEntry
+	generation uint64 // Incremented by every invalidation
+}
+
+func NewCache(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+var (
+	defaultCache     *Cache
+	defaultCacheOnce sync.Once
+)
+
+// DefaultCache returns the process-wide cache, configured by AUTH_CACHE_TTL
+// (default 30s, 0 disables it) and AUTH_CACHE_MAX_ENTRIES (default 10000)
+func DefaultCache() *Cache {
+	defaultCacheOnce.Do(func() {
+		defaultCache = NewCache(
+			config.GetEnvInt("AUTH_CACHE_MAX_ENTRIES", 10000),
+			config.GetEnvDuration("AUTH_CACHE_TTL", 30*time.Second),
+		)
+	})
+	return defaultCache
+}
+
+// InvalidateUser drops the cached snapshot of a user from the process-wide cache.
+// Call it after the transaction changing the user has committed.
+func InvalidateUser(userID uuid.UUID) {
+	DefaultCache().Invalidate(userID)
+}
+
+// InvalidateAll drops every cached snapshot from the process-wide cache (after role changes)
+func InvalidateAll() {
+	DefaultCache().InvalidateAll()
+}
+
+// Load returns the snapshot of a user, from the cache when possible
+func (c *Cache) Load(db *gorm.DB, userID uuid.UUID) (*Snapshot, error) {
+	var generation uint64
+	if c.ttl > 0 {
+		c.mu.Lock()
+		entry, ok := c.entries[userID]
+		generation = c.generation
+		c.mu.Unlock()
+
+		if ok && time.Now().Before(entry.expiresAt) {
+			c.hits.Add(1)
+			return entry.snapshot, nil
+		}
+	}
+	c.misses.Add(1)
+
+	var user models.User
+	if err := db.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	subject, err := Load(db, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{User: user, Subject: subject}
+	if c.ttl > 0 {
+		c.mu.Lock()
+		if c.generation == generation {
+			c.set(userID, snapshot)
+		}
+		c.mu.Unlock()
+	}
+	return snapshot, nil
+}
+
+// set stores a snapshot, making room if the cache is full. c.mu must be held.
+func (c *Cache) set(userID uuid.UUID, snapshot *Snapshot) {
+	now := time.Now()
+	if _, exists := c.entries[userID]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		// Drop expired entries, or an arbitrary one if none have expired
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			for id := range c.entries {
+				delete(c.entries, id)
+				break
+			}
+		}
+	}
+	c.entries[userID] = cacheEntry{snapshot: snapshot, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate drops the cached snapshot of a user
+func (c *Cache) Invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	c.generation++
+	delete(c.entries, userID)
+	c.mu.Unlock()
+
+	c.invalidations.Add(1)
+}
+
+// InvalidateAll drops every cached snapshot
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	c.generation++
+	c.entries = make(map[uuid.UUID]cacheEntry)
+	c.mu.Unlock()
+
+	c.invalidations.Add(1)
+}
+
+// Stats returns the cache's counters
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	stats := CacheStats{
+		Enabled:       c.ttl > 0,
+		TTL:           c.ttl.String(),
+		Entries:       entries,
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
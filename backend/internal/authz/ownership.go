@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ownership defines who owns the records of a resource, for the "own" permission scope
+type Ownership struct {
+	Column  string                              // Column holding the owner's user ID, used to filter list queries
+	OwnerID func(record interface{}) *uuid.UUID // Owner of a loaded record, or nil if it has none
+}
+
+// owners maps resources to their ownership hook. Resources listed here must be
+// marked Ownable in permissions.Resources.
+var owners = map[string]Ownership{
+	"users": {
+		Column: "id",
+		OwnerID: func(record interface{}) *uuid.UUID {
+			if user, ok := record.(*models.User); ok {
+				return &user.ID
+			}
+			return nil
+		},
+	},
+	"media": {
+		Column: "uploaded_by_id",
+		OwnerID: func(record interface{}) *uuid.UUID {
+			if media, ok := record.(*models.Media); ok {
+				return media.UploadedByID
+			}
+			return nil
+		},
+	},
+}
+
+// RegisterOwnership adds or replaces the ownership hook of a resource.
+// Call it during initialization, before requests are served.
+func RegisterOwnership(resource string, ownership Ownership) {
+	owners[resource] = ownership
+}
+
+// Owns reports whether the user owns a record of the resource
+func Owns(userID uuid.UUID, resource string, record interface{}) bool {
+	ownership, ok := owners[resource]
+	if !ok || ownership.OwnerID == nil {
+		return false
+	}
+
+	ownerID := ownership.OwnerID(record)
+	return ownerID != nil && *ownerID == userID
+}
+
+// Allowed reports whether an access scope covers a specific record
+func Allowed(scope permissions.Scope, userID uuid.UUID, resource string, record interface{}) bool {
+	switch scope {
+	case permissions.ScopeAny:
+		return true
+	case permissions.ScopeOwn:
+		return Owns(userID, resource, record)
+	default:
+		return false
+	}
+}
+
+// Filter restricts a query on the resource's table to the records an access scope covers
+func Filter(query *gorm.DB, scope permissions.Scope, userID uuid.UUID, resource string) *gorm.DB {
+	switch scope {
+	case permissions.ScopeAny:
+		return query
+	case permissions.ScopeOwn:
+		if ownership, ok := owners[resource]; ok && ownership.Column != "" {
+			return query.Where(clause.Eq{
+				Column: clause.Column{Table: clause.CurrentTable, Name: ownership.Column},
+				Value:  userID,
+			})
+		}
+	}
+	return query.Where("1 = 0")
+}
+
+// Access returns how far the subject may perform the action on the resource
+func (s *Subject) Access(resource, action string) permissions.Scope {
+	return s.Permissions.Access(resource, action)
+}
+
+// CanAccess reports whether the subject may perform the action on a specific record
+func (s *Subject) CanAccess(resource, action string, record interface{}) bool {
+	return Allowed(s.Access(resource, action), s.UserID, resource, record)
+}
+
+// Filter restricts a query to the records the subject may perform the action on
+func (s *Subject) Filter(query *gorm.DB, resource, action string) *gorm.DB {
+	return Filter(query, s.Access(resource, action), s.UserID, resource)
+}
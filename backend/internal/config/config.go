@@ -37,14 +37,32 @@ func InitDatabase() error {
 func MigrateModels() error {
 	log.Println("Running database migrations...")
 
+	if err := DB.SetupJoinTable(&models.User{}, "Roles", &models.UserRole{}); err != nil {
+		return fmt.Errorf("failed to set up user roles: %w", err)
+	}
+
 	err := DB.AutoMigrate(
 		&models.Role{},
 		&models.User{},
+		&models.UserRole{},
 		&models.RefreshToken{},
 		&models.PasswordReset{},
+		&models.EmailVerification{},
+		&models.EmailChange{},
+		&models.RecoveryCode{},
+		&models.Credential{},
+		&models.WebAuthnSession{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.PersonalAccessToken{},
+		&models.LoginAttempt{},
+		&models.PasswordHistory{},
+		&models.Impersonation{},
+		&models.Policy{},
 		&models.Setting{},
 		&models.Media{},
 		&models.AuditLog{},
+		&models.EmailOutbox{},
 	)
 
 	if err != nil {
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnv returns an environment variable or a default value
+func GetEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// GetEnvBool returns an environment variable parsed as a boolean or a default value
+func GetEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// GetEnvInt returns an environment variable parsed as an integer or a default value
+func GetEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+// GetEnvDuration returns an environment variable parsed as a duration or a default value
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// IsProduction reports whether ENV is "production"
+func IsProduction() bool {
+	return GetEnv("ENV", "development") == "production"
+}
+
+// FrontendURL returns the base URL of the frontend used in emailed links
+func FrontendURL() string {
+	return GetEnv("FRONTEND_URL", "http://localhost:3000")
+}
+
+// RequireEmailVerification reports whether unverified accounts are refused at login
+func RequireEmailVerification() bool {
+	return GetEnvBool("REQUIRE_EMAIL_VERIFICATION", false)
+}
+
+// AppName returns the application name used in emails
+func AppName() string {
+	return GetEnv("APP_NAME", "Go-Next App")
+}
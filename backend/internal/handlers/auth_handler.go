@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/models"
 	"github.com/your-org/go-next-template/internal/services"
 	"github.com/your-org/go-next-template/pkg/utils"
 )
@@ -30,15 +35,21 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		Email    string `json:"email" validate:"required,email"`
 		Password string `json:"password" validate:"required,min=8"`
 		Name     string `json:"name" validate:"required"`
+		Locale   string `json:"locale"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	user, err := h.authService.Register(req.Email, req.Password, req.Name)
+	// Fall back to the browser language for emails
+	if req.Locale == "" {
+		req.Locale = c.AcceptsLanguages(services.EmailLocales()...)
+	}
+
+	user, err := h.authService.Register(req.Email, req.Password, req.Name, req.Locale)
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+		return passwordErrorResponse(c, err)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -66,20 +77,17 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	accessToken, refreshToken, user, err := h.authService.Login(req.Email, req.Password)
+	// When two-factor authentication is enabled the result only carries an MFA token
+	result, err := h.authService.Login(req.Email, req.Password, clientInfo(c))
 	if err != nil {
-		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+		return loginErrorResponse(c, err)
 	}
 
-	return utils.SuccessResponse(c, fiber.Map{
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-		"user":          user,
-	})
+	return utils.SuccessResponse(c, result)
 }
 
 // RefreshToken godoc
-// @Summary Refresh access token
+// @Summary Refresh access token (rotates the refresh token)
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -95,16 +103,176 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
-	accessToken, err := h.authService.RefreshAccessToken(req.RefreshToken)
+	accessToken, refreshToken, err := h.authService.RefreshAccessToken(req.RefreshToken, clientInfo(c))
 	if err != nil {
 		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
 	}
 
 	return utils.SuccessResponse(c, fiber.Map{
-		"access_token": accessToken,
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
 	})
 }
 
+// Logout godoc
+// @Summary Logout (revoke refresh token)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh token"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.Logout(req.RefreshToken); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Logged out successfully")
+}
+
+// LogoutAll godoc
+// @Summary Logout from all devices (revoke all refresh tokens)
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to logout")
+	}
+
+	return utils.MessageResponse(c, "Logged out from all devices")
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ForgotPassword(req.Email); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to process request")
+	}
+
+	// Same response whether or not the email exists
+	return utils.MessageResponse(c, "If an account with that email exists, a password reset link has been sent")
+}
+
+// ResetPassword godoc
+// @Summary Reset password using a reset token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,min=8"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ResetPassword(req.Token, req.Password); err != nil {
+		return passwordErrorResponse(c, err)
+	}
+
+	return utils.MessageResponse(c, "Password has been reset successfully")
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token query string false "Verification token (GET)"
+// @Param body body VerifyEmailRequest false "Verification token (POST)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/verify-email [get]
+// @Router /api/v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token" validate:"required"`
+	}
+
+	req.Token = c.Query("token")
+	if req.Token == "" && c.Method() == fiber.MethodPost {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	if req.Token == "" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Missing verification token")
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Email verified successfully")
+}
+
+// ResendVerification godoc
+// @Summary Resend the email verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ResendVerificationRequest true "Account email"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ResendVerification(req.Email); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to process request")
+	}
+
+	// Same response whether or not the email exists
+	return utils.MessageResponse(c, "If the account exists and is not yet verified, a verification email has been sent")
+}
+
+// profileResponse is the current user, flagged so the UI can show a banner while an admin impersonates them
+type profileResponse struct {
+	*models.User
+	Impersonating bool                  `json:"impersonating"`
+	Impersonation *models.Impersonation `json:"impersonation,omitempty"` // Impersonator and expiry
+}
+
 // GetProfile godoc
 // @Summary Get current user profile
 // @Tags auth
@@ -118,5 +286,166 @@ func (h *AuthHandler) GetProfile(c *fiber.Ctx) error {
 		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
 	}
 
+	impersonation := middleware.GetImpersonation(c)
+	return utils.SuccessResponse(c, profileResponse{
+		User:          user,
+		Impersonating: impersonation != nil,
+		Impersonation: impersonation,
+	})
+}
+
+// UpdateProfile godoc
+// @Summary Update current user profile
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/me [put]
+func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Name   *string `json:"name"`
+		Locale *string `json:"locale"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.authService.UpdateProfile(userID, req.Name, req.Locale)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
 	return utils.SuccessResponse(c, user)
 }
+
+// ChangePassword godoc
+// @Summary Change password (signs out other devices and returns new tokens)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/change-password [post]
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.authService.ChangePassword(userID, req.CurrentPassword, req.NewPassword, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyAttempts) {
+			return loginErrorResponse(c, err)
+		}
+		return passwordErrorResponse(c, err)
+	}
+
+	return utils.SuccessResponse(c, result)
+}
+
+// RequestEmailChange godoc
+// @Summary Request an email change (a confirmation link is sent to the new address)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body ChangeEmailRequest true "New email and current password"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/change-email [post]
+func (h *AuthHandler) RequestEmailChange(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		NewEmail string `json:"new_email" validate:"required,email"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.RequestEmailChange(userID, req.NewEmail, req.Password, clientInfo(c)); err != nil {
+		if errors.Is(err, services.ErrTooManyAttempts) {
+			return loginErrorResponse(c, err)
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.MessageResponse(c, "A confirmation link has been sent to the new email address")
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change with the token from the confirmation email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ConfirmEmailChangeRequest true "Confirmation token"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/change-email/confirm [post]
+func (h *AuthHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.authService.ConfirmEmailChange(req.Token); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Email address changed successfully")
+}
+
+// clientInfo extracts client details used for auditing
+func clientInfo(c *fiber.Ctx) services.ClientInfo {
+	return services.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// loginErrorResponse maps a failed login to its status code
+func loginErrorResponse(c *fiber.Ctx, err error) error {
+	var throttleErr *services.ThrottleError
+	switch {
+	case errors.As(err, &throttleErr):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(throttleErr.RetryAfter.Seconds()))))
+		return utils.ErrorResponse(c, fiber.StatusTooManyRequests, err.Error())
+	case errors.Is(err, services.ErrEmailNotVerified):
+		return utils.ErrorResponse(c, fiber.StatusForbidden, err.Error())
+	default:
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+}
+
+// passwordErrorResponse reports password policy violations as 422 with their codes, other errors as 400
+func passwordErrorResponse(c *fiber.Ctx, err error) error {
+	var policyErr *services.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return utils.ValidationErrorResponse(c, "Password does not meet the password policy", policyErr.Violations)
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type CacheHandler struct{}
+
+func NewCacheHandler() *CacheHandler {
+	return &CacheHandler{}
+}
+
+// GetAuthCacheStats godoc
+// @Summary Hit rate and size of the user/role snapshot cache (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/cache/auth [get]
+func (h *CacheHandler) GetAuthCacheStats(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, authz.DefaultCache().Stats())
+}
+
+// ClearAuthCache godoc
+// @Summary Drop every cached user/role snapshot (admin only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/cache/auth [delete]
+func (h *CacheHandler) ClearAuthCache(c *fiber.Ctx) error {
+	authz.InvalidateAll()
+	return utils.MessageResponse(c, "Auth cache cleared")
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type EmailHandler struct {
+	outboxService *services.EmailOutboxService
+}
+
+func NewEmailHandler() *EmailHandler {
+	return &EmailHandler{
+		outboxService: services.NewEmailOutboxService(),
+	}
+}
+
+// ListEmails godoc
+// @Summary List outbox emails
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, sent, failed)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/emails [get]
+func (h *EmailHandler) ListEmails(c *fiber.Ctx) error {
+	page, limit, offset := utils.GetPagination(c)
+
+	emails, total, err := h.outboxService.List(c.Query("status"), limit, offset)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch emails")
+	}
+
+	return utils.PaginatedResponse(c, emails, page, limit, int(total))
+}
+
+// GetEmail godoc
+// @Summary Get an outbox email
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Email ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/emails/{id} [get]
+func (h *EmailHandler) GetEmail(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid email ID")
+	}
+
+	email, err := h.outboxService.Get(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, email)
+}
+
+// RetryEmail godoc
+// @Summary Re-queue a failed outbox email
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Email ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/emails/{id}/retry [post]
+func (h *EmailHandler) RetryEmail(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid email ID")
+	}
+
+	email, err := h.outboxService.Requeue(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, email)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *services.ImpersonationService
+}
+
+func NewImpersonationHandler() *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: services.NewImpersonationService(),
+	}
+}
+
+// ListImpersonations godoc
+// @Summary List impersonations, most recent first (admin only)
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Param active query bool false "Only impersonations that are still running"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonations [get]
+func (h *ImpersonationHandler) ListImpersonations(c *fiber.Ctx) error {
+	page, limit, offset := utils.GetPagination(c)
+
+	activeOnly := false
+	if active := queryBool(c, "active"); active != nil {
+		activeOnly = *active
+	}
+
+	impersonations, total, err := h.impersonationService.List(activeOnly, limit, offset)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch impersonations")
+	}
+
+	return utils.PaginatedResponse(c, impersonations, page, limit, int(total))
+}
+
+// StartImpersonation godoc
+// @Summary Issue a short-lived, non-refreshable access token to act as a user (admin only)
+// @Tags impersonation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param body body ImpersonationRequest true "Reason (e.g. support ticket)"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) StartImpersonation(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.impersonationService.Start(id, actorID, req.Reason, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Impersonation started",
+		"data":    result,
+	})
+}
+
+// StopImpersonation godoc
+// @Summary End a running impersonation (admin only)
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Impersonation ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/impersonations/{id}/stop [post]
+func (h *ImpersonationHandler) StopImpersonation(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid impersonation ID")
+	}
+
+	if err := h.impersonationService.Stop(id, actorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Impersonation stopped")
+}
+
+// StopCurrentImpersonation godoc
+// @Summary End the impersonation the request is made with
+// @Tags impersonation
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/impersonation/stop [post]
+func (h *ImpersonationHandler) StopCurrentImpersonation(c *fiber.Ctx) error {
+	impersonation := middleware.GetImpersonation(c)
+	if impersonation == nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Not impersonating")
+	}
+
+	if err := h.impersonationService.Stop(impersonation.ID, impersonation.ImpersonatorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Impersonation stopped")
+}
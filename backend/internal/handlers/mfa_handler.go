@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type MFAHandler struct {
+	mfaService  *services.MFAService
+	authService *services.AuthService
+}
+
+func NewMFAHandler() *MFAHandler {
+	return &MFAHandler{
+		mfaService:  services.NewMFAService(),
+		authService: services.NewAuthService(),
+	}
+}
+
+// SetupTOTP godoc
+// @Summary Start TOTP enrollment (returns secret, otpauth URL and QR code)
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/totp/setup [post]
+func (h *MFAHandler) SetupTOTP(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	enrollment, err := h.mfaService.SetupTOTP(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"secret":      enrollment.Secret,
+		"otpauth_url": enrollment.OTPAuthURL,
+		"qr_code":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(enrollment.QRCode),
+	})
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm TOTP enrollment with a code and receive recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body MFACodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTOTP godoc
+// @Summary Disable TOTP (requires password and a TOTP or recovery code)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body DisableMFARequest true "Password and code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/totp/disable [post]
+func (h *MFAHandler) DisableTOTP(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Password string `json:"password" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := h.mfaService.DisableTOTP(userID, req.Password, req.Code, clientInfo(c)); err != nil {
+		if errors.Is(err, services.ErrTooManyAttempts) {
+			return loginErrorResponse(c, err)
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Replace recovery codes (requires a TOTP code)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body MFACodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(userID, req.Code, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyAttempts) {
+			return loginErrorResponse(c, err)
+		}
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"recovery_codes": codes,
+	})
+}
+
+// VerifyLogin godoc
+// @Summary Complete a two-factor login with a TOTP or recovery code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param body body MFAVerifyRequest true "MFA token from login and code"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/mfa/verify [post]
+func (h *MFAHandler) VerifyLogin(c *fiber.Ctx) error {
+	var req struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.authService.CompleteMFALogin(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		return loginErrorResponse(c, err)
+	}
+
+	return utils.SuccessResponse(c, result)
+}
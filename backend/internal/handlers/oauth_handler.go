@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+// oauthStateCookie binds a login flow to the browser that started it
+const oauthStateCookie = "oauth_state"
+
+type OAuthHandler struct {
+	oauthService *services.OAuthService
+	authService  *services.AuthService
+}
+
+func NewOAuthHandler() *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: services.NewOAuthService(),
+		authService:  services.NewAuthService(),
+	}
+}
+
+// ListProviders godoc
+// @Summary List configured external login providers
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/oauth/providers [get]
+func (h *OAuthHandler) ListProviders(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, services.IdentityProviderNames())
+}
+
+// Authorize godoc
+// Sets the state binding cookie the callback requires, so both requests must be sent with credentials
+// @Summary Get the provider authorization URL (authorization code flow with PKCE)
+// @Tags oauth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/oauth/{provider}/authorize [get]
+func (h *OAuthHandler) Authorize(c *fiber.Ctx) error {
+	authURL, binding, err := h.oauthService.AuthorizationURL(c.UserContext(), c.Params("provider"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	setOAuthStateCookie(c, binding, time.Now().Add(services.OAuthStateTTL))
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"authorization_url": authURL,
+	})
+}
+
+// Callback godoc
+// @Summary Complete an external login with the code and state from the provider redirect
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param body body OAuthCallbackRequest true "Code and state"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/oauth/{provider}/callback [post]
+func (h *OAuthHandler) Callback(c *fiber.Ctx) error {
+	var req struct {
+		Code  string `json:"code" validate:"required"`
+		State string `json:"state" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// The state can only be used once, so the cookie is cleared whatever the outcome
+	binding := c.Cookies(oauthStateCookie)
+	setOAuthStateCookie(c, "", time.Unix(0, 0))
+
+	result, err := h.authService.LoginWithProvider(c.UserContext(), c.Params("provider"), req.Code, req.State, binding, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			return utils.ErrorResponse(c, fiber.StatusForbidden, err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	return utils.SuccessResponse(c, result)
+}
+
+// setOAuthStateCookie sets (or, with an expiry in the past, clears) the state binding cookie
+// OAUTH_COOKIE_SAMESITE=None is needed when the frontend and API are on different sites
+func setOAuthStateCookie(c *fiber.Ctx, value string, expires time.Time) {
+	sameSite := strings.ToLower(config.GetEnv("OAUTH_COOKIE_SAMESITE", fiber.CookieSameSiteLaxMode))
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    value,
+		Path:     "/api/v1/auth/oauth",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   config.IsProduction() || sameSite == fiber.CookieSameSiteNoneMode,
+		SameSite: sameSite,
+	})
+}
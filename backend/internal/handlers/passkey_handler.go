@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type PasskeyHandler struct {
+	passkeyService *services.PasskeyService
+	authService    *services.AuthService
+}
+
+func NewPasskeyHandler() *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: services.NewPasskeyService(),
+		authService:    services.NewAuthService(),
+	}
+}
+
+// BeginRegistration godoc
+// @Summary Start registering a passkey (returns PublicKeyCredentialCreationOptions)
+// @Tags passkeys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys/register/begin [post]
+func (h *PasskeyHandler) BeginRegistration(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	options, sessionID, err := h.passkeyService.BeginRegistration(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to start passkey registration")
+	}
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"session_id": sessionID,
+		"options":    options,
+	})
+}
+
+// FinishRegistration godoc
+// @Summary Finish registering a passkey
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body PasskeyRegisterRequest true "Session ID, name and navigator.credentials.create() result"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys/register/finish [post]
+func (h *PasskeyHandler) FinishRegistration(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		SessionID  uuid.UUID       `json:"session_id" validate:"required"`
+		Name       string          `json:"name"`
+		Credential json.RawMessage `json:"credential" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	credential, err := h.passkeyService.FinishRegistration(userID, req.SessionID, req.Name, req.Credential)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Passkey registered successfully",
+		"data":    credential,
+	})
+}
+
+// BeginLogin godoc
+// @Summary Start a passkey login (returns PublicKeyCredentialRequestOptions)
+// @Tags passkeys
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys/login/begin [post]
+func (h *PasskeyHandler) BeginLogin(c *fiber.Ctx) error {
+	options, sessionID, err := h.passkeyService.BeginLogin()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to start passkey login")
+	}
+
+	return utils.SuccessResponse(c, fiber.Map{
+		"session_id": sessionID,
+		"options":    options,
+	})
+}
+
+// FinishLogin godoc
+// @Summary Finish a passkey login and receive tokens
+// @Tags passkeys
+// @Accept json
+// @Produce json
+// @Param body body PasskeyLoginRequest true "Session ID and navigator.credentials.get() result"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys/login/finish [post]
+func (h *PasskeyHandler) FinishLogin(c *fiber.Ctx) error {
+	var req struct {
+		SessionID  uuid.UUID       `json:"session_id" validate:"required"`
+		Credential json.RawMessage `json:"credential" validate:"required"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	result, err := h.authService.LoginWithPasskey(req.SessionID, req.Credential, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrEmailNotVerified) {
+			return utils.ErrorResponse(c, fiber.StatusForbidden, err.Error())
+		}
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	return utils.SuccessResponse(c, result)
+}
+
+// ListPasskeys godoc
+// @Summary List the current user's passkeys
+// @Tags passkeys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys [get]
+func (h *PasskeyHandler) ListPasskeys(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	credentials, err := h.passkeyService.List(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch passkeys")
+	}
+
+	return utils.SuccessResponse(c, credentials)
+}
+
+// DeletePasskey godoc
+// @Summary Remove one of the current user's passkeys
+// @Tags passkeys
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Passkey ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/passkeys/{id} [delete]
+func (h *PasskeyHandler) DeletePasskey(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid passkey ID")
+	}
+
+	if err := h.passkeyService.Delete(userID, id); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Passkey removed successfully")
+}
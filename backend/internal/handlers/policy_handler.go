@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/policy"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type PolicyHandler struct {
+	policyService *services.PolicyService
+}
+
+func NewPolicyHandler() *PolicyHandler {
+	return &PolicyHandler{
+		policyService: services.NewPolicyService(),
+	}
+}
+
+// policyRequest is the body accepted by CreatePolicy and UpdatePolicy
+type policyRequest struct {
+	Name        *string                 `json:"name"`
+	Description *string                 `json:"description"`
+	Effect      *string                 `json:"effect"`
+	Resources   []string                `json:"resources"`
+	Actions     []string                `json:"actions"`
+	Conditions  models.PolicyConditions `json:"conditions"`
+	IsActive    *bool                   `json:"is_active"`
+}
+
+func (r policyRequest) input() services.PolicyInput {
+	return services.PolicyInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Effect:      r.Effect,
+		Resources:   r.Resources,
+		Actions:     r.Actions,
+		Conditions:  r.Conditions,
+		IsActive:    r.IsActive,
+	}
+}
+
+// ListPolicies godoc
+// @Summary List authorization policies (admin only)
+// @Tags policies
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policies [get]
+func (h *PolicyHandler) ListPolicies(c *fiber.Ctx) error {
+	policies, err := h.policyService.List()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch policies")
+	}
+
+	return utils.SuccessResponse(c, policies)
+}
+
+// GetPolicy godoc
+// @Summary Get an authorization policy (admin only)
+// @Tags policies
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policies/{id} [get]
+func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid policy ID")
+	}
+
+	p, err := h.policyService.Get(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, p)
+}
+
+// CreatePolicy godoc
+// @Summary Create an authorization policy (admin only)
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body PolicyRequest true "Policy"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/admin/policies [post]
+func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req policyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	p, err := h.policyService.Create(req.input(), actorID, clientInfo(c))
+	if err != nil {
+		return policyErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Policy created successfully",
+		"data":    p,
+	})
+}
+
+// UpdatePolicy godoc
+// @Summary Update an authorization policy (admin only)
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Param body body PolicyRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policies/{id} [put]
+func (h *PolicyHandler) UpdatePolicy(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid policy ID")
+	}
+
+	var req policyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	p, err := h.policyService.Update(id, req.input(), actorID, clientInfo(c))
+	if err != nil {
+		return policyErrorResponse(c, err)
+	}
+
+	return utils.SuccessResponse(c, p)
+}
+
+// DeletePolicy godoc
+// @Summary Delete an authorization policy (admin only)
+// @Tags policies
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Policy ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policies/{id} [delete]
+func (h *PolicyHandler) DeletePolicy(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid policy ID")
+	}
+
+	if err := h.policyService.Delete(id, actorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Policy deleted successfully")
+}
+
+// ExplainPolicy godoc
+// @Summary Dry-run an authorization decision and explain it (admin only)
+// @Description Evaluates role permissions and active policies for any user without performing the action.
+// @Description An unsaved draft policy can be included to test it before saving.
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body ExplainPolicyRequest true "Request to evaluate"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/policies/explain [post]
+func (h *PolicyHandler) ExplainPolicy(c *fiber.Ctx) error {
+	var req struct {
+		UserID     uuid.UUID              `json:"user_id"`
+		Resource   string                 `json:"resource"`
+		Action     string                 `json:"action"`
+		ResourceID *uuid.UUID             `json:"resource_id"`
+		Attributes map[string]interface{} `json:"attributes"`
+		Context    struct {
+			Time      *time.Time `json:"time"`
+			IPAddress string     `json:"ip"`
+			Method    string     `json:"method"`
+			Path      string     `json:"path"`
+		} `json:"context"`
+		Policy *policyRequest `json:"policy"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+	if req.UserID == uuid.Nil || req.Resource == "" || req.Action == "" {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "user_id, resource and action are required")
+	}
+
+	input := services.ExplainInput{
+		UserID:     req.UserID,
+		Resource:   req.Resource,
+		Action:     req.Action,
+		ResourceID: req.ResourceID,
+		Attributes: req.Attributes,
+		Context: policy.Context{
+			Time:      time.Now(),
+			IPAddress: req.Context.IPAddress,
+			Method:    req.Context.Method,
+			Path:      req.Context.Path,
+		},
+	}
+	if req.Context.Time != nil {
+		input.Context.Time = *req.Context.Time
+	}
+	if req.Policy != nil {
+		draft := models.Policy{Name: "draft"}
+		services.ApplyPolicyInput(&draft, req.Policy.input())
+		input.Draft = &draft
+	}
+
+	decision, err := h.policyService.Explain(input)
+	if err != nil {
+		return policyErrorResponse(c, err)
+	}
+
+	return utils.SuccessResponse(c, decision)
+}
+
+func policyErrorResponse(c *fiber.Ctx, err error) error {
+	var validationErr *policy.ValidationError
+	if errors.As(err, &validationErr) {
+		return utils.ValidationErrorResponse(c, "Invalid policy", validationErr.Errors)
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		roleService: services.NewRoleService(),
+	}
+}
+
+// roleRequest is the body accepted by CreateRole and UpdateRole
+type roleRequest struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	Permissions map[string]interface{} `json:"permissions"`
+	ParentID    *string                `json:"parent_id"` // "" removes the parent
+	IsActive    *bool                  `json:"is_active"`
+}
+
+func (r roleRequest) input() (services.RoleInput, error) {
+	input := services.RoleInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.Permissions,
+		IsActive:    r.IsActive,
+	}
+
+	if r.ParentID != nil {
+		parentID := uuid.Nil
+		if *r.ParentID != "" {
+			id, err := uuid.Parse(*r.ParentID)
+			if err != nil {
+				return input, errors.New("invalid parent role ID")
+			}
+			parentID = id
+		}
+		input.ParentID = &parentID
+	}
+
+	return input, nil
+}
+
+// ListRoles godoc
+// @Summary List roles with their user counts (admin only)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	roles, err := h.roleService.List()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch roles")
+	}
+
+	return utils.SuccessResponse(c, roles)
+}
+
+// GetRole godoc
+// @Summary Get a role (admin only)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid role ID")
+	}
+
+	role, err := h.roleService.Get(id)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.SuccessResponse(c, role)
+}
+
+// CreateRole godoc
+// @Summary Create a role (admin only)
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RoleRequest true "Role details"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/admin/roles [post]
+func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req roleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	input, err := req.input()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	role, err := h.roleService.Create(input, actorID, clientInfo(c))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// UpdateRole godoc
+// @Summary Update a role (admin only)
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param body body RoleRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid role ID")
+	}
+
+	var req roleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	input, err := req.input()
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	role, err := h.roleService.Update(id, input, actorID, clientInfo(c))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return utils.SuccessResponse(c, role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role that no user holds and no role inherits from (admin only)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid role ID")
+	}
+
+	if err := h.roleService.Delete(id, actorID, clientInfo(c)); err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return utils.MessageResponse(c, "Role deleted successfully")
+}
+
+// ListPermissions godoc
+// @Summary List the resources and actions role permissions can grant (admin only)
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, permissions.Resources)
+}
+
+func roleErrorResponse(c *fiber.Ctx, err error) error {
+	var docErr *permissions.DocumentError
+	if errors.As(err, &docErr) {
+		return utils.ValidationErrorResponse(c, "Invalid permission document", docErr.Errors)
+	}
+	return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+}
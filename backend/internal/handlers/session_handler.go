@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		sessionService: services.NewSessionService(),
+	}
+}
+
+// ListSessions godoc
+// @Summary List the devices the current user is signed in on
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions [get]
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	sessions, err := h.sessionService.List(userID, middleware.GetCurrentSessionID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch sessions")
+	}
+
+	return utils.SuccessResponse(c, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Sign the current user out of one device
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := h.sessionService.Revoke(userID, sessionID, userID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Session revoked successfully")
+}
+
+// ListUserSessions godoc
+// @Summary List a user's active sessions (admin only)
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/sessions [get]
+func (h *SessionHandler) ListUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	sessions, err := h.sessionService.List(userID, middleware.GetCurrentSessionID(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch sessions")
+	}
+
+	return utils.SuccessResponse(c, sessions)
+}
+
+// RevokeUserSession godoc
+// @Summary Sign a user out of one device (admin only)
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/sessions/{sessionId} [delete]
+func (h *SessionHandler) RevokeUserSession(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	sessionID, err := uuid.Parse(c.Params("sessionId"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid session ID")
+	}
+
+	if err := h.sessionService.Revoke(userID, sessionID, actorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Session revoked successfully")
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type TokenHandler struct {
+	tokenService *services.PersonalAccessTokenService
+}
+
+func NewTokenHandler() *TokenHandler {
+	return &TokenHandler{
+		tokenService: services.NewPersonalAccessTokenService(),
+	}
+}
+
+// ListTokens godoc
+// @Summary List the current user's personal access tokens
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens [get]
+func (h *TokenHandler) ListTokens(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	tokens, err := h.tokenService.List(userID)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch tokens")
+	}
+
+	return utils.SuccessResponse(c, tokens)
+}
+
+// CreateToken godoc
+// @Summary Create a personal access token (the token value is only returned once)
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateTokenRequest true "Name, scopes and optional expiry"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens [post]
+func (h *TokenHandler) CreateToken(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Name      string     `json:"name" validate:"required"`
+		Scopes    []string   `json:"scopes" validate:"required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	token, plaintext, err := h.tokenService.Create(userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Token created. Copy it now, it will not be shown again",
+		"data": fiber.Map{
+			"token":   plaintext,
+			"details": token,
+		},
+	})
+}
+
+// RevokeToken godoc
+// @Summary Revoke one of the current user's personal access tokens
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Token ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/auth/tokens/{id} [delete]
+func (h *TokenHandler) RevokeToken(c *fiber.Ctx) error {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid token ID")
+	}
+
+	if err := h.tokenService.Revoke(userID, id); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Token revoked successfully")
+}
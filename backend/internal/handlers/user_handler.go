@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/middleware"
+	"github.com/your-org/go-next-template/internal/services"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+type UserHandler struct {
+	userService     *services.UserService
+	throttleService *services.LoginThrottleService
+}
+
+func NewUserHandler() *UserHandler {
+	return &UserHandler{
+		userService:     services.NewUserService(),
+		throttleService: services.NewLoginThrottleService(),
+	}
+}
+
+// ListUsers godoc
+// @Summary List users (admins, or users:read; users:read:own lists only the caller)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param role_id query string false "Filter by role ID"
+// @Param is_active query bool false "Filter by active status"
+// @Param email_verified query bool false "Filter by email verification"
+// @Param search query string false "Search name or email"
+// @Param sort query string false "Sort by created_at (default), name, email or last_login_at"
+// @Param order query string false "asc or desc (default)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users [get]
+// @Router /api/v1/users [get]
+func (h *UserHandler) ListUsers(c *fiber.Ctx) error {
+	page, limit, offset := utils.GetPagination(c)
+
+	authorized, err := middleware.AuthorizedScope(c, "users", "read")
+	if err != nil {
+		return err
+	}
+
+	filter := services.UserFilter{
+		IsActive:      queryBool(c, "is_active"),
+		EmailVerified: queryBool(c, "email_verified"),
+		Search:        c.Query("search"),
+		Sort:          c.Query("sort"),
+		Order:         c.Query("order"),
+		Authorized:    authorized,
+	}
+	if roleID := c.Query("role_id"); roleID != "" {
+		id, err := uuid.Parse(roleID)
+		if err != nil {
+			return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid role ID")
+		}
+		filter.RoleID = &id
+	}
+
+	users, total, err := h.userService.List(filter, limit, offset)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch users")
+	}
+
+	return utils.PaginatedResponse(c, users, page, limit, int(total))
+}
+
+// GetUser godoc
+// @Summary Get a user (admins, or users:read; users:read:own only the caller)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [get]
+// @Router /api/v1/users/{id} [get]
+func (h *UserHandler) GetUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userService.Get(id)
+	if err != nil {
+		return middleware.RecordNotFound(c, "users", "read")
+	}
+	if err := middleware.Authorize(c, "users", "read", user); err != nil {
+		return err
+	}
+
+	return utils.SuccessResponse(c, user)
+}
+
+// CreateUser godoc
+// @Summary Create a user and email them an invite to set a password (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateUserRequest true "User details"
+// @Success 201 {object} map[string]interface{}
+// @Router /api/v1/admin/users [post]
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	var req struct {
+		Email  string     `json:"email" validate:"required,email"`
+		Name   string     `json:"name" validate:"required"`
+		RoleID *uuid.UUID `json:"role_id"`
+		Locale string     `json:"locale"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.userService.Create(services.CreateUserInput{
+		Email:  req.Email,
+		Name:   req.Name,
+		RoleID: req.RoleID,
+		Locale: req.Locale,
+	}, actorID, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "User created and invited successfully",
+		"data":    user,
+	})
+}
+
+// UpdateUser godoc
+// @Summary Update a user's profile fields (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param body body UpdateUserRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id} [put]
+func (h *UserHandler) UpdateUser(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req struct {
+		Email         *string `json:"email"`
+		Name          *string `json:"name"`
+		Locale        *string `json:"locale"`
+		EmailVerified *bool   `json:"email_verified"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.userService.Update(id, services.UpdateUserInput{
+		Email:         req.Email,
+		Name:          req.Name,
+		Locale:        req.Locale,
+		EmailVerified: req.EmailVerified,
+	}, actorID, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, user)
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user and sign them out everywhere (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/deactivate [post]
+func (h *UserHandler) DeactivateUser(c *fiber.Ctx) error {
+	return h.setActive(c, false)
+}
+
+// ReactivateUser godoc
+// @Summary Reactivate a deactivated user (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/reactivate [post]
+func (h *UserHandler) ReactivateUser(c *fiber.Ctx) error {
+	return h.setActive(c, true)
+}
+
+func (h *UserHandler) setActive(c *fiber.Ctx, active bool) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	user, err := h.userService.SetActive(id, active, actorID, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, user)
+}
+
+// AssignRole godoc
+// @Summary Change a user's primary role (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param body body AssignRoleRequest true "Role ID (null removes the role)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *UserHandler) AssignRole(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req struct {
+		RoleID *uuid.UUID `json:"role_id"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.userService.AssignRole(id, req.RoleID, actorID, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, user)
+}
+
+// SetUserRoles godoc
+// @Summary Replace a user's additional roles (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param body body SetUserRolesRequest true "Role IDs (empty removes all additional roles)"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *UserHandler) SetUserRoles(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	var req struct {
+		RoleIDs []uuid.UUID `json:"role_ids"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	user, err := h.userService.SetRoles(id, req.RoleIDs, actorID, clientInfo(c))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.SuccessResponse(c, user)
+}
+
+// ForcePasswordReset godoc
+// @Summary Invalidate a user's password and email them a reset link (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/reset-password [post]
+func (h *UserHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.userService.ForcePasswordReset(id, actorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return utils.MessageResponse(c, "Password reset email sent")
+}
+
+// UnlockUser godoc
+// @Summary Lift a login lockout and clear failed attempts (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *fiber.Ctx) error {
+	actorID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusUnauthorized, err.Error())
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utils.ErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	if err := h.throttleService.Unlock(id, actorID, clientInfo(c)); err != nil {
+		return utils.ErrorResponse(c, fiber.StatusNotFound, err.Error())
+	}
+
+	return utils.MessageResponse(c, "User unlocked successfully")
+}
+
+// queryBool parses an optional boolean query parameter (nil when absent or invalid)
+func queryBool(c *fiber.Ctx, key string) *bool {
+	value := c.Query(key)
+	if value == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil
+	}
+	return &b
+}
@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"errors"
+	"log"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/your-org/go-next-template/internal/models"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/policy"
+	"github.com/your-org/go-next-template/internal/services"
 )
 
 // AdminOnly middleware ensures user has admin role
 func AdminOnly(c *fiber.Ctx) error {
-	user, err := GetCurrentUser(c)
+	subject, err := GetCurrentSubject(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"success": false,
@@ -15,21 +21,29 @@ func AdminOnly(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if user is admin
-	if !user.IsAdmin() {
+	// Admins are users whose merged role permissions grant "*" without deny rules
+	if !subject.IsAdmin() {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"success": false,
 			"error":   "Admin access required",
 		})
 	}
 
+	// Personal access tokens only reach admin routes with full scope and no deny rules
+	if token := GetCurrentToken(c); token != nil && !token.HasFullScope() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Token scope does not allow admin access",
+		})
+	}
+
 	return c.Next()
 }
 
-// RoleRequired middleware ensures user has specific role
+// RoleRequired middleware ensures user holds a role, directly or through role inheritance
 func RoleRequired(role string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		user, err := GetCurrentUser(c)
+		subject, err := GetCurrentSubject(c)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
@@ -37,7 +51,7 @@ func RoleRequired(role string) fiber.Handler {
 			})
 		}
 
-		if user.Role == nil || user.Role.Name != role {
+		if !subject.HasRole(role) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error":   "Insufficient permissions",
@@ -48,10 +62,12 @@ func RoleRequired(role string) fiber.Handler {
 	}
 }
 
-// PermissionRequired middleware ensures user has specific permission
+// PermissionRequired middleware ensures the merged permissions of the user's roles grant an action.
+// Users whose permission is limited to their own records pass too, so handlers behind it
+// check records with Authorize and filter lists with AuthorizedScope.
 func PermissionRequired(resource, action string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		user, err := GetCurrentUser(c)
+		subject, err := GetCurrentSubject(c)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"success": false,
@@ -59,20 +75,95 @@ func PermissionRequired(resource, action string) fiber.Handler {
 			})
 		}
 
-		if user.Role == nil {
+		if !subject.HasRoles() {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error":   "No role assigned",
 			})
 		}
 
-		if !user.Role.HasPermission(resource, action) {
+		if !subject.Can(resource, action) {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 				"success": false,
 				"error":   "Insufficient permissions",
 			})
 		}
 
+		// Personal access tokens are further limited to their scopes
+		if token := GetCurrentToken(c); token != nil && !token.HasScope(resource, action) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "Token scope does not allow this action",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// PolicyRequired middleware decides access with role permissions and attribute-based policies.
+// On routes with an :id parameter the record's attributes are loaded for policy conditions.
+func PolicyRequired(resource, action string) fiber.Handler {
+	policyService := services.NewPolicyService()
+
+	return func(c *fiber.Ctx) error {
+		user, err := GetCurrentUser(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "User not authenticated",
+			})
+		}
+		subject, err := GetCurrentSubject(c)
+		if err != nil {
+			return err
+		}
+
+		var resourceID *uuid.UUID
+		if id, err := uuid.Parse(c.Params("id")); err == nil {
+			resourceID = &id
+		}
+
+		ctx := policy.Context{
+			Time:      time.Now(),
+			IPAddress: c.IP(),
+			Method:    c.Method(),
+			Path:      c.Path(),
+		}
+		decision, err := policyService.Evaluate(user, subject, resource, action, resourceID, nil, ctx)
+		if errors.Is(err, services.ErrResourceNotFound) {
+			// Only users allowed to act without the record's attributes learn that it is missing
+			decision, err = policyService.Evaluate(user, subject, resource, action, nil, nil, ctx)
+			if err == nil && decision.Allowed {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"success": false,
+					"error":   "Resource not found",
+				})
+			}
+		}
+		if err != nil {
+			log.Printf("Policy evaluation failed for %s:%s: %v", resource, action, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to check permissions",
+			})
+		}
+
+		if !decision.Allowed {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "Insufficient permissions",
+			})
+		}
+
+		// Personal access tokens are further limited to their scopes
+		if token := GetCurrentToken(c); token != nil && !token.HasScope(resource, action) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"error":   "Token scope does not allow this action",
+			})
+		}
+
 		return c.Next()
 	}
 }
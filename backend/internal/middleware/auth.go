@@ -5,12 +5,14 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
 	"github.com/your-org/go-next-template/internal/config"
 	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/services"
 	"github.com/your-org/go-next-template/pkg/utils"
 )
 
-// AuthRequired middleware verifies JWT token
+// AuthRequired middleware verifies a JWT access token or a personal access token
 func AuthRequired(c *fiber.Ctx) error {
 	// Get Authorization header
 	authHeader := c.Get("Authorization")
@@ -32,22 +34,72 @@ func AuthRequired(c *fiber.Ctx) error {
 
 	tokenString := parts[1]
 
-	// Verify token
-	claims, err := utils.VerifyToken(tokenString)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"error":   "Invalid or expired token",
-		})
-	}
-
-	// Get user from database
 	var user models.User
-	if err := config.DB.Preload("Role").First(&user, "id = ?", claims.UserID).Error; err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"error":   "User not found",
-		})
+
+	if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+		// Personal access token (scripts, CI)
+		token, tokenUser, err := services.NewPersonalAccessTokenService().Authenticate(tokenString)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid or expired token",
+			})
+		}
+		user = *tokenUser
+		c.Locals("token", token)
+	} else {
+		// Verify token
+		claims, err := utils.VerifyToken(tokenString)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Invalid or expired token",
+			})
+		}
+
+		// Get user from the snapshot cache, falling back to the database
+		snapshot, err := authz.DefaultCache().Load(config.DB, claims.UserID)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "User not found",
+			})
+		}
+		user = snapshot.User // Copy, so handlers cannot modify the cached user
+		c.Locals("subject", snapshot.Subject)
+
+		// Tokens issued before a password change, logout-all or role change are stale
+		if claims.Version != user.TokenVersion || claims.Role != user.RoleName() {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error":   "Token has been invalidated",
+			})
+		}
+
+		if claims.ImpersonatorID != nil {
+			// Impersonation tokens end when the impersonation is stopped or expires
+			impersonation, err := services.NewImpersonationService().Active(snapshot, claims.SessionID, *claims.ImpersonatorID)
+			if err != nil {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"success": false,
+					"error":   "Impersonation has ended",
+				})
+			}
+			c.Locals("impersonation", impersonation)
+		} else if claims.SessionID != uuid.Nil {
+			// A revoked session (logout, device sign-out) ends its access tokens immediately.
+			// Live sessions are remembered on the snapshot, which revocation invalidates.
+			if !snapshot.SessionActive(claims.SessionID) {
+				if !services.NewSessionService().IsActive(claims.UserID, claims.SessionID) {
+					return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+						"success": false,
+						"error":   "Session has been revoked",
+					})
+				}
+				snapshot.RememberSession(claims.SessionID)
+			}
+			c.Locals("sessionID", claims.SessionID)
+		}
 	}
 
 	// Check if user is active
@@ -58,6 +110,25 @@ func AuthRequired(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check if email is verified (only when required)
+	if config.RequireEmailVerification() && !user.EmailVerified {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "Email not verified",
+		})
+	}
+
+	// Every write made while impersonating is audited before it runs
+	if impersonation := GetImpersonation(c); impersonation != nil && !isReadOnlyMethod(c.Method()) {
+		client := services.ClientInfo{IPAddress: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+		if err := services.NewImpersonationService().RecordWrite(impersonation, c.Method(), c.Path(), client); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"error":   "Failed to record impersonated request",
+			})
+		}
+	}
+
 	// Store user in context
 	c.Locals("user", &user)
 	c.Locals("userID", user.ID)
@@ -65,6 +136,10 @@ func AuthRequired(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+func isReadOnlyMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
 // GetCurrentUser retrieves the authenticated user from context
 func GetCurrentUser(c *fiber.Ctx) (*models.User, error) {
 	user, ok := c.Locals("user").(*models.User)
@@ -74,6 +149,26 @@ func GetCurrentUser(c *fiber.Ctx) (*models.User, error) {
 	return user, nil
 }
 
+// GetCurrentSubject returns the effective roles and permissions of the authenticated user,
+// loading them on first use within the request
+func GetCurrentSubject(c *fiber.Ctx) (*authz.Subject, error) {
+	if subject, ok := c.Locals("subject").(*authz.Subject); ok {
+		return subject, nil
+	}
+
+	user, err := GetCurrentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := authz.Load(config.DB, user)
+	if err != nil {
+		return nil, err
+	}
+	c.Locals("subject", subject)
+	return subject, nil
+}
+
 // GetCurrentUserID retrieves the authenticated user ID from context
 func GetCurrentUserID(c *fiber.Ctx) (uuid.UUID, error) {
 	userID, ok := c.Locals("userID").(uuid.UUID)
@@ -82,3 +177,48 @@ func GetCurrentUserID(c *fiber.Ctx) (uuid.UUID, error) {
 	}
 	return userID, nil
 }
+
+// GetCurrentSessionID returns the session of the access token used for the request (uuid.Nil for personal access tokens)
+func GetCurrentSessionID(c *fiber.Ctx) uuid.UUID {
+	sessionID, _ := c.Locals("sessionID").(uuid.UUID)
+	return sessionID
+}
+
+// GetCurrentToken returns the personal access token used for the request, or nil for JWT sessions
+func GetCurrentToken(c *fiber.Ctx) *models.PersonalAccessToken {
+	token, _ := c.Locals("token").(*models.PersonalAccessToken)
+	return token
+}
+
+// GetImpersonation returns the impersonation behind the request's access token, or nil
+// when the user is acting as themself
+func GetImpersonation(c *fiber.Ctx) *models.Impersonation {
+	impersonation, _ := c.Locals("impersonation").(*models.Impersonation)
+	return impersonation
+}
+
+// NotImpersonating middleware rejects requests made with an impersonation token
+// Use it for account-security routes (password, email, tokens, MFA, passkeys) only the account owner may use
+func NotImpersonating(c *fiber.Ctx) error {
+	if GetImpersonation(c) != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "This action is not allowed while impersonating",
+		})
+	}
+
+	return c.Next()
+}
+
+// SessionRequired middleware rejects requests authenticated with a personal access token
+// Use it for account-security routes (tokens, MFA, passkeys) that scripts should never reach
+func SessionRequired(c *fiber.Ctx) error {
+	if GetCurrentToken(c) != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error":   "This endpoint cannot be used with a personal access token",
+		})
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/gorm"
+)
+
+// AccessScope returns how far the current user may perform an action on a resource.
+// Personal access tokens narrow it further to their own scopes.
+func AccessScope(c *fiber.Ctx, resource, action string) (permissions.Scope, uuid.UUID, error) {
+	subject, err := GetCurrentSubject(c)
+	if err != nil {
+		return permissions.ScopeNone, uuid.Nil, err
+	}
+
+	scope := subject.Access(resource, action)
+	if token := GetCurrentToken(c); token != nil {
+		tokenScopes, _ := permissions.ParseRules(token.Scopes)
+		if tokenScope := tokenScopes.Access(resource, action); tokenScope < scope {
+			scope = tokenScope
+		}
+	}
+
+	return scope, subject.UserID, nil
+}
+
+// Authorize checks that the current user may perform an action on a specific record.
+// Handlers call it after loading the record; routes are also guarded by
+// PermissionRequired, which lets "own" permissions through.
+//
+//	if err := middleware.Authorize(c, "users", "read", user); err != nil {
+//		return err
+//	}
+func Authorize(c *fiber.Ctx, resource, action string, record interface{}) error {
+	scope, userID, err := AccessScope(c, resource, action)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	if !authz.Allowed(scope, userID, resource, record) {
+		return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+	}
+	return nil
+}
+
+// RecordNotFound is the error for a record that could not be loaded. Only users who may
+// act on every record learn that it is missing; the others get the same 403 as for a
+// record they do not own.
+func RecordNotFound(c *fiber.Ctx, resource, action string) error {
+	scope, _, err := AccessScope(c, resource, action)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	if scope != permissions.ScopeAny {
+		return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+	}
+	return fiber.NewError(fiber.StatusNotFound, "Resource not found")
+}
+
+// AuthorizedScope returns a GORM scope restricting a list query to the records the
+// current user may perform an action on. Services apply it with query.Scopes.
+//
+//	authorized, err := middleware.AuthorizedScope(c, "users", "read")
+func AuthorizedScope(c *fiber.Ctx, resource, action string) (func(*gorm.DB) *gorm.DB, error) {
+	scope, userID, err := AccessScope(c, resource, action)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "User not authenticated")
+	}
+
+	return func(query *gorm.DB) *gorm.DB {
+		return authz.Filter(query, scope, userID, resource)
+	}, nil
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var (
+	callerID = uuid.New()
+	otherID  = uuid.New()
+)
+
+// testApp mounts the user directory routes the way routes.SetupRoutes does, with the
+// authenticated subject and token injected and an in-memory user store
+func testApp(t *testing.T, rules []string, token *models.PersonalAccessToken, users map[uuid.UUID]*models.User) *fiber.App {
+	t.Helper()
+
+	set, err := permissions.ParseRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject := &authz.Subject{
+		UserID:      callerID,
+		Roles:       []models.Role{{Name: "member"}},
+		Permissions: set,
+	}
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			code := fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				code = e.Code
+			}
+			return c.SendStatus(code)
+		},
+	})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("subject", subject)
+		if token != nil {
+			c.Locals("token", token)
+		}
+		return c.Next()
+	})
+
+	app.Get("/users/:id", PermissionRequired("users", "read"), func(c *fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+		user, ok := users[id]
+		if !ok {
+			return RecordNotFound(c, "users", "read")
+		}
+		if err := Authorize(c, "users", "read", user); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestUserDirectoryOwnership(t *testing.T) {
+	users := map[uuid.UUID]*models.User{
+		callerID: {ID: callerID},
+		otherID:  {ID: otherID},
+	}
+	missingID := uuid.New()
+
+	tests := []struct {
+		name   string
+		rules  []string
+		token  *models.PersonalAccessToken
+		target uuid.UUID
+		want   int
+	}{
+		{name: "any scope reads another user", rules: []string{"users:read"}, target: otherID, want: fiber.StatusOK},
+		{name: "any scope learns a user is missing", rules: []string{"users:read"}, target: missingID, want: fiber.StatusNotFound},
+		{name: "own scope reads the caller", rules: []string{"users:read:own"}, target: callerID, want: fiber.StatusOK},
+		{name: "own scope cannot read another user", rules: []string{"users:read:own"}, target: otherID, want: fiber.StatusForbidden},
+		{name: "own scope cannot probe for missing users", rules: []string{"users:read:own"}, target: missingID, want: fiber.StatusForbidden},
+		{name: "no permission", rules: []string{"media:read"}, target: callerID, want: fiber.StatusForbidden},
+		{name: "deny wins over own", rules: []string{"users:read:own", "!users:read"}, target: callerID, want: fiber.StatusForbidden},
+		{
+			name:   "token narrows any to own",
+			rules:  []string{"users:read"},
+			token:  &models.PersonalAccessToken{Scopes: models.StringList{"users:read:own"}},
+			target: otherID,
+			want:   fiber.StatusForbidden,
+		},
+		{
+			name:   "token narrowed to own still reads the caller",
+			rules:  []string{"users:read"},
+			token:  &models.PersonalAccessToken{Scopes: models.StringList{"users:read:own"}},
+			target: callerID,
+			want:   fiber.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := testApp(t, tt.rules, tt.token, users)
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/"+tt.target.String(), nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizedScope(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		rules []string
+		want  string // Fragment of the WHERE clause, or "" for none
+	}{
+		{name: "any", rules: []string{"users:read"}, want: ""},
+		{name: "own", rules: []string{"users:read:own"}, want: `"users"."id" = $1`},
+		{name: "none", rules: []string{"media:read"}, want: "1 = 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := testApp(t, tt.rules, nil, nil)
+			var sql string
+			app.Get("/list", func(c *fiber.Ctx) error {
+				authorized, err := AuthorizedScope(c, "users", "read")
+				if err != nil {
+					return err
+				}
+				stmt := db.Model(&models.User{}).Scopes(authorized).Find(&[]models.User{}).Statement
+				sql = stmt.SQL.String()
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/list", nil)); err != nil {
+				t.Fatal(err)
+			}
+			if sql == "" {
+				t.Fatal("no query was built")
+			}
+			if tt.want == "" {
+				if strings.Contains(sql, `"users"."id" =`) || strings.Contains(sql, "1 = 0") {
+					t.Errorf("any scope filtered the query: %s", sql)
+				}
+				return
+			}
+			if !strings.Contains(sql, tt.want) {
+				t.Errorf("query %q does not contain %q", sql, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/your-org/go-next-template/internal/config"
+)
+
+// RateLimit limits every client IP to RATE_LIMIT_MAX requests per RATE_LIMIT_WINDOW
+func RateLimit() fiber.Handler {
+	return newLimiter(
+		config.GetEnvInt("RATE_LIMIT_MAX", 300),
+		config.GetEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+	)
+}
+
+// AuthRateLimit is a stricter per-IP limit for credential endpoints (login, register, password reset, ...)
+func AuthRateLimit() fiber.Handler {
+	return newLimiter(
+		config.GetEnvInt("AUTH_RATE_LIMIT_MAX", 20),
+		config.GetEnvDuration("AUTH_RATE_LIMIT_WINDOW", time.Minute),
+	)
+}
+
+func newLimiter(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"success": false,
+				"error":   "Too many requests, please try again later",
+			})
+		},
+	})
+}
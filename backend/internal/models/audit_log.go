@@ -7,13 +7,36 @@ import (
 	"gorm.io/gorm"
 )
 
+// Audit actions for security events
+const (
+	AuditActionRefreshTokenReuse   = "refresh_token_reuse"
+	AuditActionAccountLocked       = "account_locked"
+	AuditActionAccountUnlocked     = "account_unlocked"
+	AuditActionSessionRevoked      = "session_revoked"
+	AuditActionUserCreated         = "user_created"
+	AuditActionUserUpdated         = "user_updated"
+	AuditActionUserDeactivated     = "user_deactivated"
+	AuditActionUserReactivated     = "user_reactivated"
+	AuditActionUserRoleChanged     = "user_role_changed"
+	AuditActionPasswordResetForced = "password_reset_forced"
+	AuditActionRoleCreated         = "role_created"
+	AuditActionRoleUpdated         = "role_updated"
+	AuditActionRoleDeleted         = "role_deleted"
+	AuditActionPolicyCreated       = "policy_created"
+	AuditActionPolicyUpdated       = "policy_updated"
+	AuditActionPolicyDeleted       = "policy_deleted"
+	AuditActionImpersonationStart  = "impersonation_started"
+	AuditActionImpersonationStop   = "impersonation_stopped"
+	AuditActionImpersonatedWrite   = "impersonated_write"
+)
+
 // AuditLog represents an audit trail for important actions
 type AuditLog struct {
 	ID         uuid.UUID              `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	UserID     *uuid.UUID             `gorm:"type:uuid;index" json:"user_id"`
 	User       *User                  `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"user,omitempty"`
 	Action     string                 `gorm:"size:50;not null;index" json:"action"` // 'create', 'update', 'delete'
-	EntityType string                 `gorm:"size:100;index" json:"entity_type"` // 'user', 'post', etc.
+	EntityType string                 `gorm:"size:100;index" json:"entity_type"`    // 'user', 'post', etc.
 	EntityID   *uuid.UUID             `gorm:"type:uuid;index" json:"entity_id"`
 	Changes    map[string]interface{} `gorm:"type:jsonb" json:"changes"` // Old/new values
 	IPAddress  string                 `gorm:"size:45" json:"ip_address"`
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Credential represents a WebAuthn credential (passkey) registered by a user
+type Credential struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User            User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name            string     `gorm:"size:100" json:"name"` // User-given label, e.g. "MacBook Touch ID"
+	CredentialID    []byte     `gorm:"type:bytea;uniqueIndex;not null" json:"-"`
+	PublicKey       []byte     `gorm:"type:bytea;not null" json:"-"` // COSE-encoded public key
+	AttestationType string     `gorm:"size:50" json:"attestation_type"`
+	AAGUID          []byte     `gorm:"type:bytea" json:"-"`
+	SignCount       uint32     `gorm:"default:0" json:"sign_count"`
+	Transports      StringList `gorm:"type:jsonb" json:"transports"` // 'usb', 'nfc', 'ble', 'internal', 'hybrid'
+	BackupEligible  bool       `gorm:"default:false" json:"backup_eligible"`
+	BackupState     bool       `gorm:"default:false" json:"backup_state"` // Synced passkey
+	CloneWarning    bool       `gorm:"default:false" json:"clone_warning"`
+	LastUsedAt      *time.Time `json:"last_used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook for Credential
+func (c *Credential) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebAuthnSession stores the challenge of an in-progress WebAuthn ceremony
+type WebAuthnSession struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`  // Empty for discoverable (passwordless) logins
+	Purpose   string     `gorm:"size:20;not null" json:"purpose"` // 'registration', 'login'
+	Data      string     `gorm:"type:text;not null" json:"-"`     // JSON-encoded webauthn.SessionData
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook for WebAuthnSession
+func (ws *WebAuthnSession) BeforeCreate(tx *gorm.DB) error {
+	if ws.ID == uuid.Nil {
+		ws.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if the ceremony has timed out
+func (ws *WebAuthnSession) IsExpired() bool {
+	return time.Now().After(ws.ExpiresAt)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Email outbox statuses
+const (
+	EmailStatusPending = "pending"
+	EmailStatusSent    = "sent"
+	EmailStatusFailed  = "failed"
+)
+
+// EmailOutbox represents an email queued for delivery by the background worker
+type EmailOutbox struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	To            string     `gorm:"size:255;not null;index" json:"to"`
+	Subject       string     `gorm:"size:500;not null" json:"subject"`
+	TextBody      string     `gorm:"type:text" json:"-"`                                   // Never returned: contains reset, invite and verification links
+	HTMLBody      string     `gorm:"type:text" json:"-"`                                   // Cleared once the email is sent
+	Template      string     `gorm:"size:100" json:"template"`                             // 'verify_email', 'password_reset', etc.
+	Status        string     `gorm:"size:20;not null;default:pending;index" json:"status"` // 'pending', 'sent', 'failed'
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	MaxAttempts   int        `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName keeps the outbox table name singular
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
+
+// BeforeCreate hook to generate UUID
+func (e *EmailOutbox) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = EmailStatusPending
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = time.Now()
+	}
+	return nil
+}
+
+// IsFailed checks if delivery has permanently failed
+func (e *EmailOutbox) IsFailed() bool {
+	return e.Status == EmailStatusFailed
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Impersonation is a time-limited session in which an admin acts as another user
+type Impersonation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"` // Also the "sid" claim of the impersonation token
+	ImpersonatorID uuid.UUID  `gorm:"type:uuid;not null;index" json:"impersonator_id"`
+	Impersonator   *User      `gorm:"foreignKey:ImpersonatorID;constraint:OnDelete:CASCADE" json:"impersonator,omitempty"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"` // User being impersonated
+	User           *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	Reason         string     `gorm:"type:text;not null" json:"reason"` // Support ticket or justification
+	IPAddress      string     `gorm:"size:45" json:"ip_address"`
+	UserAgent      string     `gorm:"type:text" json:"user_agent"`
+	ExpiresAt      time.Time  `gorm:"not null;index" json:"expires_at"` // Hard limit; impersonation tokens are never refreshed
+	EndedAt        *time.Time `json:"ended_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// BeforeCreate hook for Impersonation
+func (i *Impersonation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the impersonation has neither been stopped nor expired
+func (i *Impersonation) IsActive() bool {
+	return i.EndedAt == nil && time.Now().Before(i.ExpiresAt)
+}
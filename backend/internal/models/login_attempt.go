@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginAttempt records a password or MFA attempt for throttling and lockout
+type LoginAttempt struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Email     string    `gorm:"size:255;index" json:"email"` // Lower-cased; recorded even for unknown accounts
+	IPAddress string    `gorm:"size:45;index" json:"ip_address"`
+	Success   bool      `gorm:"default:false" json:"success"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate hook for LoginAttempt
+func (la *LoginAttempt) BeforeCreate(tx *gorm.DB) error {
+	if la.ID == uuid.Nil {
+		la.ID = uuid.New()
+	}
+	return nil
+}
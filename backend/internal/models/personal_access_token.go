@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenPrefix marks personal access tokens so they can be told apart from JWTs
+const PersonalAccessTokenPrefix = "gnt_"
+
+// ScopeAll grants a personal access token everything its owner's role allows
+const ScopeAll = "*"
+
+// PersonalAccessToken is a long-lived API token created by a user for scripts and CI
+type PersonalAccessToken struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	TokenPrefix string     `gorm:"size:16;not null" json:"token_prefix"` // First characters, shown to identify the token
+	TokenHash   string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes      StringList `gorm:"type:jsonb" json:"scopes"` // ["media:read", "settings:update"] or ["*"]
+	ExpiresAt   *time.Time `json:"expires_at"`               // Never expires when empty
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for PersonalAccessToken
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if token has expired
+func (t *PersonalAccessToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// IsRevoked checks if token has been revoked
+func (t *PersonalAccessToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsValid checks if token can still be used
+func (t *PersonalAccessToken) IsValid() bool {
+	return !t.IsExpired() && !t.IsRevoked()
+}
+
+// HasScope checks if token was granted a resource/action pair
+// Scopes use the rule grammar of package permissions, e.g. "media:read", "media:*" or "!users:delete"
+func (t *PersonalAccessToken) HasScope(resource, action string) bool {
+	set, _ := permissions.ParseRules(t.Scopes)
+	return set.Allows(resource, action)
+}
+
+// HasFullScope checks if token was granted everything ("*") with no deny rule narrowing it
+func (t *PersonalAccessToken) HasFullScope() bool {
+	set, err := permissions.ParseRules(t.Scopes)
+	return err == nil && set.Unrestricted()
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Policy effects
+const (
+	PolicyEffectAllow = "allow"
+	PolicyEffectDeny  = "deny"
+)
+
+// Policy is an attribute-based authorization rule evaluated on top of role permissions
+// (see package policy). A policy applies when the resource and action match and all of
+// its conditions hold.
+type Policy struct {
+	ID          uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name        string           `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string           `gorm:"size:255" json:"description"`
+	Effect      string           `gorm:"size:10;not null" json:"effect"` // 'allow' or 'deny'
+	Resources   StringList       `gorm:"type:jsonb" json:"resources"`    // ["users"] or ["*"]
+	Actions     StringList       `gorm:"type:jsonb" json:"actions"`      // ["update", "delete"] or ["*"]
+	Conditions  PolicyConditions `gorm:"type:jsonb" json:"conditions"`   // All must hold
+	IsActive    bool             `gorm:"default:true;index" json:"is_active"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (p *Policy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PolicyCondition compares a request attribute with a value, e.g.
+// {"attribute": "context.hour", "operator": "between", "value": [9, 17]} or
+// {"attribute": "resource.owner_id", "operator": "eq", "value_from": "subject.id"}
+type PolicyCondition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom string      `json:"value_from,omitempty"` // Compare with another attribute instead of Value
+}
+
+// PolicyConditions represents a list of policy conditions stored as JSONB
+type PolicyConditions []PolicyCondition
+
+// Value implements driver.Valuer interface for GORM
+func (c PolicyConditions) Value() (driver.Value, error) {
+	if c == nil {
+		return json.Marshal([]PolicyCondition{})
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for GORM
+func (c *PolicyConditions) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+
+	var result []PolicyCondition
+	if err := json.Unmarshal(bytes, &result); err != nil {
+		return err
+	}
+
+	*c = result
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/permissions"
 	"gorm.io/gorm"
 )
 
@@ -12,7 +13,8 @@ type Role struct {
 	ID          uuid.UUID              `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	Name        string                 `gorm:"size:50;uniqueIndex;not null" json:"name"` // 'admin', 'member', 'guest'
 	Description string                 `gorm:"size:255" json:"description"`
-	Permissions map[string]interface{} `gorm:"type:jsonb" json:"permissions"` // {"users": "crud", "posts": "read"}
+	Permissions map[string]interface{} `gorm:"type:jsonb" json:"permissions"`    // {"users": ["*", "!delete"], "media": "read,update"}
+	ParentID    *uuid.UUID             `gorm:"type:uuid;index" json:"parent_id"` // Role this one inherits permissions from
 	IsActive    bool                   `gorm:"default:true" json:"is_active"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
@@ -26,37 +28,14 @@ func (r *Role) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// HasPermission checks if role has specific permission
+// HasPermission checks if role itself has specific permission (see package permissions for the grammar).
+// Inherited permissions and a user's other roles are evaluated by package authz.
 func (r *Role) HasPermission(resource, action string) bool {
 	if r.Permissions == nil {
 		return false
 	}
 
-	permission, ok := r.Permissions[resource]
-	if !ok {
-		return false
-	}
-
-	// Check if permission is "all" or contains the action
-	switch v := permission.(type) {
-	case string:
-		return v == "all" || v == action || contains(v, action)
-	case []interface{}:
-		for _, p := range v {
-			if p == action || p == "all" {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func contains(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
+	// Invalid entries are rejected when a role is saved; any left over are ignored here
+	set, _ := permissions.ParseDocument(r.Permissions)
+	return set.Allows(resource, action)
 }
@@ -14,9 +14,16 @@ type User struct {
 	PasswordHash  string     `gorm:"size:255;not null" json:"-"` // Never return password in JSON
 	Name          string     `gorm:"size:255;not null" json:"name"`
 	RoleID        *uuid.UUID `gorm:"type:uuid" json:"role_id"`
-	Role          *Role      `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+	Role          *Role      `gorm:"foreignKey:RoleID" json:"role,omitempty"`     // Primary role, named in access tokens
+	Roles         []Role     `gorm:"many2many:user_roles" json:"roles,omitempty"` // Additional roles
+	Locale        string     `gorm:"size:10;default:'en'" json:"locale"`          // Preferred language for emails ('en', 'th', ...)
 	EmailVerified bool       `gorm:"default:false" json:"email_verified"`
 	IsActive      bool       `gorm:"default:true" json:"is_active"`
+	TOTPSecret    string     `gorm:"size:64" json:"-"` // Set during enrollment, active once TOTPEnabled
+	TOTPEnabled   bool       `gorm:"default:false" json:"totp_enabled"`
+	TOTPLastStep  int64      `gorm:"default:0" json:"-"`          // Last accepted time step (replay protection)
+	LockedUntil   *time.Time `json:"locked_until"`                // Set after too many failed logins
+	TokenVersion  int        `gorm:"default:0;not null" json:"-"` // Embedded in access tokens; bumping it invalidates them
 	LastLoginAt   *time.Time `json:"last_login_at"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
@@ -30,22 +37,41 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// IsAdmin checks if user is an admin
-func (u *User) IsAdmin() bool {
+// RoleName returns the name of the user's primary role, or an empty string
+func (u *User) RoleName() string {
 	if u.Role == nil {
-		return false
+		return ""
 	}
-	return u.Role.Name == "admin"
+	return u.Role.Name
+}
+
+// IsLocked checks if user is temporarily locked out
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
+// UserRole assigns an additional role to a user
+type UserRole struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RoleID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // RefreshToken represents a refresh token for JWT authentication
 type RefreshToken struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
-	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
-	Token     string    `gorm:"size:500;uniqueIndex;not null" json:"token"`
-	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Token        string     `gorm:"size:500;uniqueIndex;not null" json:"token"`
+	FamilyID     uuid.UUID  `gorm:"type:uuid;index" json:"family_id"` // Shared by all tokens rotated from the same login
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"-"`               // Token issued when this one was rotated
+	DeviceName   string     `gorm:"size:100" json:"device_name"`      // e.g. "Chrome on macOS", carried over on rotation
+	UserAgent    string     `gorm:"type:text" json:"user_agent"`
+	IPAddress    string     `gorm:"size:45" json:"ip_address"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 // BeforeCreate hook for RefreshToken
@@ -61,12 +87,30 @@ func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.ExpiresAt)
 }
 
+// IsRevoked checks if refresh token has been revoked (logout or rotation)
+func (rt *RefreshToken) IsRevoked() bool {
+	return rt.RevokedAt != nil
+}
+
+// IsRotated checks if refresh token has already been exchanged for a new one
+func (rt *RefreshToken) IsRotated() bool {
+	return rt.ReplacedByID != nil
+}
+
+// Family returns the token family ID (tokens created before rotation existed are their own family)
+func (rt *RefreshToken) Family() uuid.UUID {
+	if rt.FamilyID == uuid.Nil {
+		return rt.ID
+	}
+	return rt.FamilyID
+}
+
 // PasswordReset represents a password reset token
 type PasswordReset struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
 	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
 	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
-	Token     string    `gorm:"size:500;uniqueIndex;not null" json:"token"`
+	Token     string    `gorm:"size:500;uniqueIndex;not null" json:"-"` // SHA-256 hash of the emailed token
 	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
 	Used      bool      `gorm:"default:false" json:"used"`
 	CreatedAt time.Time `json:"created_at"`
@@ -89,3 +133,62 @@ func (pr *PasswordReset) IsExpired() bool {
 func (pr *PasswordReset) IsValid() bool {
 	return !pr.Used && !pr.IsExpired()
 }
+
+// EmailVerification represents an email verification token
+type EmailVerification struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Token     string    `gorm:"size:500;uniqueIndex;not null" json:"-"` // SHA-256 hash of the emailed token
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	Used      bool      `gorm:"default:false" json:"used"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for EmailVerification
+func (ev *EmailVerification) BeforeCreate(tx *gorm.DB) error {
+	if ev.ID == uuid.Nil {
+		ev.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if email verification token is expired
+func (ev *EmailVerification) IsExpired() bool {
+	return time.Now().After(ev.ExpiresAt)
+}
+
+// IsValid checks if email verification token is valid (not used and not expired)
+func (ev *EmailVerification) IsValid() bool {
+	return !ev.Used && !ev.IsExpired()
+}
+
+// EmailChange represents a pending change of a user's email address, confirmed from the new address
+type EmailChange struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	NewEmail  string    `gorm:"size:255;not null" json:"new_email"`
+	Token     string    `gorm:"size:500;uniqueIndex;not null" json:"-"` // SHA-256 hash of the emailed token
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	Used      bool      `gorm:"default:false" json:"used"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for EmailChange
+func (ec *EmailChange) BeforeCreate(tx *gorm.DB) error {
+	if ec.ID == uuid.Nil {
+		ec.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if email change token is expired
+func (ec *EmailChange) IsExpired() bool {
+	return time.Now().After(ec.ExpiresAt)
+}
+
+// IsValid checks if email change token is valid (not used and not expired)
+func (ec *EmailChange) IsValid() bool {
+	return !ec.Used && !ec.IsExpired()
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a user to an account at an external identity provider (OIDC/OAuth2)
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Provider    string     `gorm:"size:50;not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"` // 'google', 'github', etc.
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`       // Provider's user ID ("sub" claim)
+	Email       string     `gorm:"size:255" json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook for UserIdentity
+func (ui *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if ui.ID == uuid.Nil {
+		ui.ID = uuid.New()
+	}
+	return nil
+}
+
+// OAuthState stores an in-progress authorization code flow (state, nonce and PKCE verifier)
+type OAuthState struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Provider     string    `gorm:"size:50;not null" json:"provider"`
+	StateHash    string    `gorm:"size:64;uniqueIndex;not null" json:"-"` // SHA-256 hash of the state parameter
+	Nonce        string    `gorm:"size:100;not null" json:"-"`
+	CodeVerifier string    `gorm:"size:128;not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook for OAuthState
+func (st *OAuthState) BeforeCreate(tx *gorm.DB) error {
+	if st.ID == uuid.Nil {
+		st.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if the authorization flow has timed out
+func (st *OAuthState) IsExpired() bool {
+	return time.Now().After(st.ExpiresAt)
+}
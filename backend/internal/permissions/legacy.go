@@ -0,0 +1,116 @@
+package permissions
+
+import "strings"
+
+// legacyLetters maps the letters of pre-grammar values such as "crud" to actions
+var legacyLetters = map[rune]string{
+	'c': "create",
+	'r': "read",
+	'u': "update",
+	'd': "delete",
+}
+
+// legacyActions expands a pre-grammar letter value such as "crud" or "r" into actions.
+// ok is false for anything else, including values already in the rule grammar.
+func legacyActions(value string) (actions []string, ok bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, false
+	}
+	seen := make(map[rune]bool, len(value))
+	for _, letter := range value {
+		action, known := legacyLetters[letter]
+		if !known {
+			return nil, false
+		}
+		if !seen[letter] {
+			seen[letter] = true
+			actions = append(actions, action)
+		}
+	}
+	return actions, true
+}
+
+// UpgradeLegacy rewrites the pre-grammar letter values of a role document as explicit
+// actions, e.g. {"users": "crud"} becomes {"users": ["create", "read", "update", "delete"]}.
+// Without it such values would parse as an action named "crud" and grant nothing.
+// It reports whether the document changed; the input is never modified.
+func UpgradeLegacy(doc map[string]interface{}) (map[string]interface{}, bool) {
+	upgraded := make(map[string]interface{}, len(doc))
+	changed := false
+
+	for resource, value := range doc {
+		items := valueItems(value)
+
+		var actions []interface{}
+		legacy := false
+		for _, item := range items {
+			if expanded, ok := legacyActions(item); ok {
+				legacy = true
+				for _, action := range expanded {
+					actions = append(actions, action)
+				}
+				continue
+			}
+			actions = append(actions, strings.TrimSpace(item))
+		}
+
+		if legacy {
+			upgraded[resource] = actions
+			changed = true
+		} else {
+			upgraded[resource] = value
+		}
+	}
+	return upgraded, changed
+}
+
+// GrantAll returns a copy of doc that grants everything ("*") and keeps the deny rules
+// of doc, which still win over "*". It upgrades roles whose access used to follow the
+// name "admin". It reports whether the document changed; the input is never modified.
+func GrantAll(doc map[string]interface{}) (map[string]interface{}, bool) {
+	set, _ := ParseDocument(doc)
+	for _, rule := range set {
+		if !rule.Deny && rule.Resource == Wildcard && rule.Action == Wildcard && rule.Scope == ScopeAny {
+			return doc, false
+		}
+	}
+
+	upgraded := make(map[string]interface{}, len(doc)+1)
+	for resource, value := range doc {
+		var denied []interface{}
+		for _, item := range valueItems(value) {
+			if item = strings.TrimSpace(item); strings.HasPrefix(item, "!") {
+				denied = append(denied, item)
+			}
+		}
+		if len(denied) > 0 {
+			upgraded[resource] = denied
+		}
+	}
+
+	existing, _ := upgraded[Wildcard].([]interface{})
+	upgraded[Wildcard] = append([]interface{}{Wildcard}, existing...)
+	return upgraded, true
+}
+
+// valueItems splits a document value into its entries. It returns nil for values
+// Validate rejects.
+func valueItems(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil
+			}
+			items = append(items, s)
+		}
+	case []string:
+		items = v
+	}
+	return items
+}
@@ -0,0 +1,296 @@
+// Package permissions parses and evaluates role permissions and token scopes.
+//
+// A rule has the form "[!]resource:action[:scope]":
+//
+//	users:read        allow reading users
+//	users:*           allow every action on users
+//	*:read            allow reading every resource
+//	*                 allow everything (same as *:*)
+//	media:update:own  allow updating only media the user owns
+//	!users:delete     deny deleting users, even if another rule allows it
+//
+// The scope is "any" (the default) or "own"; which records a user owns is defined per
+// resource by package authz. Deny rules always win over allow rules and cannot be scoped.
+// "all" is accepted as an alias for "*".
+//
+// Roles store their rules as a document mapping resources to actions, where each
+// value is a comma-separated string or an array:
+//
+//	{"users": ["*", "!delete"], "media": "read,update:own", "*": "read"}
+package permissions
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Wildcard matches any resource or action
+const Wildcard = "*"
+
+// legacyAll is the pre-grammar spelling of Wildcard
+const legacyAll = "all"
+
+var (
+	errEmptyRule  = errors.New("empty rule")
+	errScopedDeny = errors.New("deny rules cannot have a scope")
+)
+
+// Scope is how far an allow rule reaches
+type Scope int
+
+const (
+	ScopeNone Scope = iota // No access
+	ScopeOwn               // Only records the user owns
+	ScopeAny               // Every record
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeOwn:
+		return "own"
+	case ScopeAny:
+		return "any"
+	default:
+		return "none"
+	}
+}
+
+// Rule is a single parsed permission
+type Rule struct {
+	Resource string
+	Action   string
+	Scope    Scope // ScopeAny or ScopeOwn; deny rules always use ScopeAny
+	Deny     bool
+}
+
+// String returns the rule in its canonical "[!]resource:action[:own]" form
+func (r Rule) String() string {
+	s := r.Resource + ":" + r.Action
+	if r.Scope == ScopeOwn {
+		s += ":own"
+	}
+	if r.Deny {
+		return "!" + s
+	}
+	return s
+}
+
+// Matches reports whether the rule applies to the resource/action pair
+func (r Rule) Matches(resource, action string) bool {
+	return (r.Resource == Wildcard || r.Resource == resource) &&
+		(r.Action == Wildcard || r.Action == action)
+}
+
+// ParseRule parses a rule in "[!]resource:action[:scope]" form
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+
+	rule := Rule{Scope: ScopeAny}
+	if strings.HasPrefix(s, "!") {
+		rule.Deny = true
+		s = strings.TrimSpace(s[1:])
+	}
+	if s == "" {
+		return Rule{}, errEmptyRule
+	}
+	if s == Wildcard {
+		rule.Resource, rule.Action = Wildcard, Wildcard
+		return rule, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Rule{}, fmt.Errorf("%q: expected resource:action or resource:action:scope", s)
+	}
+
+	var err error
+	if rule.Resource, err = parseName(parts[0]); err != nil {
+		return Rule{}, fmt.Errorf("%q: resource %w", s, err)
+	}
+	if rule.Action, err = parseName(parts[1]); err != nil {
+		return Rule{}, fmt.Errorf("%q: action %w", s, err)
+	}
+
+	if len(parts) == 3 {
+		switch strings.TrimSpace(parts[2]) {
+		case "any":
+		case "own":
+			rule.Scope = ScopeOwn
+		default:
+			return Rule{}, fmt.Errorf("%q: scope must be \"own\" or \"any\"", s)
+		}
+		if rule.Deny {
+			return Rule{}, fmt.Errorf("%q: %w", s, errScopedDeny)
+		}
+	}
+	return rule, nil
+}
+
+// parseName validates a resource or action name: "*" or lowercase letters, digits and underscores
+func parseName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == Wildcard || name == legacyAll {
+		return Wildcard, nil
+	}
+	if name == "" {
+		return "", errors.New("is empty")
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return "", fmt.Errorf("%q may only contain lowercase letters, digits and underscores", name)
+		}
+	}
+	return name, nil
+}
+
+// Set is a collection of rules evaluated together
+type Set []Rule
+
+// ParseRules parses a list of rule strings, e.g. personal access token scopes.
+// Invalid rules are skipped and reported in the returned error.
+func ParseRules(rules []string) (Set, error) {
+	set := make(Set, 0, len(rules))
+	var problems []string
+	for _, s := range rules {
+		rule, err := ParseRule(s)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		set = append(set, rule)
+	}
+
+	if len(problems) > 0 {
+		return set, errors.New(strings.Join(problems, "; "))
+	}
+	return set, nil
+}
+
+// ParseDocument parses a role permission document. Invalid entries are skipped and
+// reported in the returned *DocumentError, so callers that only evaluate permissions
+// may ignore the error.
+func ParseDocument(doc map[string]interface{}) (Set, error) {
+	var set Set
+	var problems []Error
+	add := func(resource, format string, args ...interface{}) {
+		problems = append(problems, Error{Resource: resource, Message: fmt.Sprintf(format, args...)})
+	}
+
+	resources := make([]string, 0, len(doc))
+	for resource := range doc {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		var actions []string
+		switch v := doc[resource].(type) {
+		case string:
+			actions = strings.Split(v, ",")
+		case []interface{}:
+			for _, item := range v {
+				action, ok := item.(string)
+				if !ok {
+					add(resource, "actions must be strings")
+					continue
+				}
+				actions = append(actions, action)
+			}
+		case []string:
+			actions = v
+		default:
+			add(resource, "value must be a string or an array of actions")
+			continue
+		}
+
+		if len(actions) == 0 {
+			add(resource, "at least one action is required")
+		}
+		for _, action := range actions {
+			action = strings.TrimSpace(action)
+			prefix := ""
+			if strings.HasPrefix(action, "!") {
+				prefix, action = "!", action[1:]
+			}
+
+			rule, err := ParseRule(prefix + resource + ":" + action)
+			if err != nil {
+				add(resource, "%v", err)
+				continue
+			}
+			set = append(set, rule)
+		}
+	}
+
+	if len(problems) > 0 {
+		return set, &DocumentError{Errors: problems}
+	}
+	return set, nil
+}
+
+// Access returns how far the set grants the action on the resource.
+// A matching deny rule always wins; otherwise the widest matching allow rule applies.
+func (s Set) Access(resource, action string) Scope {
+	scope := ScopeNone
+	for _, rule := range s {
+		if !rule.Matches(resource, action) {
+			continue
+		}
+		if rule.Deny {
+			return ScopeNone
+		}
+		if rule.Scope > scope {
+			scope = rule.Scope
+		}
+	}
+	return scope
+}
+
+// Allows reports whether the set grants the action on the resource for at least some
+// records. Use Access when the answer may be limited to owned records.
+func (s Set) Allows(resource, action string) bool {
+	return s.Access(resource, action) != ScopeNone
+}
+
+// Covers reports whether the set grants everything an allow rule could match.
+// Wildcards are expanded over the registered resources and actions.
+func (s Set) Covers(rule Rule) bool {
+	if rule.Deny {
+		return true
+	}
+
+	for _, pair := range Expand(rule) {
+		if s.Access(pair.Resource, pair.Action) < rule.Scope {
+			return false
+		}
+	}
+	return true
+}
+
+// Unrestricted reports whether the set grants everything ("*") without any deny rule
+func (s Set) Unrestricted() bool {
+	all := false
+	for _, rule := range s {
+		if rule.Deny {
+			return false
+		}
+		if rule.Resource == Wildcard && rule.Action == Wildcard && rule.Scope == ScopeAny {
+			all = true
+		}
+	}
+	return all
+}
+
+// Strings returns the rules in canonical form
+func (s Set) Strings() []string {
+	result := make([]string, 0, len(s))
+	for _, rule := range s {
+		result = append(result, rule.String())
+	}
+	return result
+}
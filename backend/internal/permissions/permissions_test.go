@@ -0,0 +1,412 @@
+package permissions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rule
+		wantErr bool
+	}{
+		{in: "users:read", want: Rule{Resource: "users", Action: "read", Scope: ScopeAny}},
+		{in: "  users:read  ", want: Rule{Resource: "users", Action: "read", Scope: ScopeAny}},
+		{in: "users:read:any", want: Rule{Resource: "users", Action: "read", Scope: ScopeAny}},
+		{in: "media:update:own", want: Rule{Resource: "media", Action: "update", Scope: ScopeOwn}},
+		{in: "users:*", want: Rule{Resource: "users", Action: Wildcard, Scope: ScopeAny}},
+		{in: "*:read", want: Rule{Resource: Wildcard, Action: "read", Scope: ScopeAny}},
+		{in: "*", want: Rule{Resource: Wildcard, Action: Wildcard, Scope: ScopeAny}},
+		{in: "*:*", want: Rule{Resource: Wildcard, Action: Wildcard, Scope: ScopeAny}},
+		{in: "all", wantErr: true},
+		{in: "users:all", want: Rule{Resource: "users", Action: Wildcard, Scope: ScopeAny}},
+		{in: "all:read", want: Rule{Resource: Wildcard, Action: "read", Scope: ScopeAny}},
+		{in: "audit_logs:read", want: Rule{Resource: "audit_logs", Action: "read", Scope: ScopeAny}},
+		{in: "v2:read", want: Rule{Resource: "v2", Action: "read", Scope: ScopeAny}},
+		{in: "!users:delete", want: Rule{Resource: "users", Action: "delete", Scope: ScopeAny, Deny: true}},
+		{in: "! users:delete", want: Rule{Resource: "users", Action: "delete", Scope: ScopeAny, Deny: true}},
+		{in: "!*", want: Rule{Resource: Wildcard, Action: Wildcard, Scope: ScopeAny, Deny: true}},
+		{in: "!users:delete:any", wantErr: true},
+		{in: "!media:update:own", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "   ", wantErr: true},
+		{in: "!", wantErr: true},
+		{in: "users", wantErr: true},
+		{in: "users:", wantErr: true},
+		{in: ":read", wantErr: true},
+		{in: "users:read:own:extra", wantErr: true},
+		{in: "users:read:mine", wantErr: true},
+		{in: "Users:read", wantErr: true},
+		{in: "users:Read", wantErr: true},
+		{in: "2fa:read", wantErr: true},
+		{in: "users-admin:read", wantErr: true},
+		{in: "users:re ad", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRule(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRule(%q) = %+v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRule(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRuleStringRoundTrip(t *testing.T) {
+	for _, in := range []string{"users:read", "media:update:own", "*:*", "!users:delete", "*:read"} {
+		rule, err := ParseRule(in)
+		if err != nil {
+			t.Fatalf("ParseRule(%q): %v", in, err)
+		}
+		again, err := ParseRule(rule.String())
+		if err != nil || again != rule {
+			t.Errorf("%q -> %q -> %+v, %v", in, rule.String(), again, err)
+		}
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         map[string]interface{}
+		want        []string
+		wantProblem []string // resources reported in the DocumentError
+	}{
+		{
+			name: "empty",
+			doc:  map[string]interface{}{},
+			want: []string{},
+		},
+		{
+			name: "comma separated string",
+			doc:  map[string]interface{}{"users": "read, update"},
+			want: []string{"users:read", "users:update"},
+		},
+		{
+			name: "array with deny and scope",
+			doc:  map[string]interface{}{"media": []interface{}{"*", "!delete", "update:own"}},
+			want: []string{"media:*", "!media:delete", "media:update:own"},
+		},
+		{
+			name: "string slice",
+			doc:  map[string]interface{}{"roles": []string{"read"}},
+			want: []string{"roles:read"},
+		},
+		{
+			name: "wildcard resource",
+			doc:  map[string]interface{}{"*": "read"},
+			want: []string{"*:read"},
+		},
+		{
+			name: "legacy all",
+			doc:  map[string]interface{}{"settings": "all"},
+			want: []string{"settings:*"},
+		},
+		{
+			name: "resources are sorted",
+			doc:  map[string]interface{}{"users": "read", "audit_logs": "read", "media": "read"},
+			want: []string{"audit_logs:read", "media:read", "users:read"},
+		},
+		{
+			name:        "invalid entries are skipped and reported",
+			doc:         map[string]interface{}{"users": "read,Bad", "media": 3, "roles": []interface{}{"read", 1}},
+			want:        []string{"roles:read", "users:read"},
+			wantProblem: []string{"media", "roles", "users"},
+		},
+		{
+			name:        "empty array",
+			doc:         map[string]interface{}{"users": []interface{}{}},
+			want:        []string{},
+			wantProblem: []string{"users"},
+		},
+		{
+			name:        "scoped deny",
+			doc:         map[string]interface{}{"media": "!update:own"},
+			want:        []string{},
+			wantProblem: []string{"media"},
+		},
+		{
+			name:        "invalid resource name",
+			doc:         map[string]interface{}{"Users": "read"},
+			want:        []string{},
+			wantProblem: []string{"Users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseDocument(tt.doc)
+			if got := set.Strings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+
+			if len(tt.wantProblem) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var docErr *DocumentError
+			if !errors.As(err, &docErr) {
+				t.Fatalf("error = %v, want *DocumentError", err)
+			}
+			seen := map[string]bool{}
+			for _, problem := range docErr.Errors {
+				seen[problem.Resource] = true
+			}
+			for _, resource := range tt.wantProblem {
+				if !seen[resource] {
+					t.Errorf("no problem reported for %q in %v", resource, docErr)
+				}
+			}
+			if len(seen) != len(tt.wantProblem) {
+				t.Errorf("problems reported for %v, want %v", seen, tt.wantProblem)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, rules ...string) Set {
+	t.Helper()
+	set, err := ParseRules(rules)
+	if err != nil {
+		t.Fatalf("ParseRules(%v): %v", rules, err)
+	}
+	return set
+}
+
+func TestAccess(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		resource string
+		action   string
+		want     Scope
+	}{
+		{"no rules", nil, "users", "read", ScopeNone},
+		{"exact", []string{"users:read"}, "users", "read", ScopeAny},
+		{"other action", []string{"users:read"}, "users", "update", ScopeNone},
+		{"other resource", []string{"users:read"}, "media", "read", ScopeNone},
+		{"action wildcard", []string{"users:*"}, "users", "delete", ScopeAny},
+		{"resource wildcard", []string{"*:read"}, "media", "read", ScopeAny},
+		{"resource wildcard other action", []string{"*:read"}, "media", "delete", ScopeNone},
+		{"everything", []string{"*"}, "settings", "update", ScopeAny},
+		{"own", []string{"media:update:own"}, "media", "update", ScopeOwn},
+		{"widest allow wins", []string{"media:update:own", "media:update"}, "media", "update", ScopeAny},
+		{"widest allow wins in any order", []string{"media:update", "media:update:own"}, "media", "update", ScopeAny},
+		{"own with wildcard any", []string{"media:*:own", "*:read"}, "media", "read", ScopeAny},
+		{"own with wildcard own", []string{"media:*:own", "*:read"}, "media", "update", ScopeOwn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := mustParse(t, tt.rules...)
+			if got := set.Access(tt.resource, tt.action); got != tt.want {
+				t.Errorf("Access(%s, %s) = %v, want %v", tt.resource, tt.action, got, tt.want)
+			}
+			if got := set.Allows(tt.resource, tt.action); got != (tt.want != ScopeNone) {
+				t.Errorf("Allows(%s, %s) = %v, want %v", tt.resource, tt.action, got, tt.want != ScopeNone)
+			}
+		})
+	}
+}
+
+func TestDenyPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		resource string
+		action   string
+		want     Scope
+	}{
+		{"deny after allow", []string{"users:*", "!users:delete"}, "users", "delete", ScopeNone},
+		{"deny before allow", []string{"!users:delete", "users:*"}, "users", "delete", ScopeNone},
+		{"deny beats everything", []string{"*", "!users:delete"}, "users", "delete", ScopeNone},
+		{"deny leaves other actions", []string{"*", "!users:delete"}, "users", "read", ScopeAny},
+		{"deny leaves other resources", []string{"*", "!users:delete"}, "media", "delete", ScopeAny},
+		{"deny beats own", []string{"media:update:own", "!media:update"}, "media", "update", ScopeNone},
+		{"wildcard deny action", []string{"*", "!users:*"}, "users", "read", ScopeNone},
+		{"wildcard deny resource", []string{"users:delete", "!*:delete"}, "users", "delete", ScopeNone},
+		{"deny everything", []string{"users:read", "!*"}, "users", "read", ScopeNone},
+		{"deny alone grants nothing", []string{"!users:delete"}, "users", "read", ScopeNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := mustParse(t, tt.rules...)
+			if got := set.Access(tt.resource, tt.action); got != tt.want {
+				t.Errorf("Access(%s, %s) = %v, want %v", tt.resource, tt.action, got, tt.want)
+			}
+		})
+	}
+
+	// Deny rules from a document apply the same way
+	set, err := ParseDocument(map[string]interface{}{"*": "*", "users": []interface{}{"!delete"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Allows("users", "delete") {
+		t.Error("document deny did not win over the wildcard allow")
+	}
+	if set.Unrestricted() {
+		t.Error("a set with a deny rule reported as unrestricted")
+	}
+}
+
+func TestUnrestricted(t *testing.T) {
+	tests := []struct {
+		rules []string
+		want  bool
+	}{
+		{[]string{"*"}, true},
+		{[]string{"*:*", "users:read"}, true},
+		{[]string{"*:*:own"}, false},
+		{[]string{"users:*", "media:*"}, false},
+		{[]string{"*", "!users:delete"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.rules...).Unrestricted(); got != tt.want {
+			t.Errorf("Unrestricted(%v) = %v, want %v", tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		want    map[string]interface{}
+		changed bool
+	}{
+		{
+			name:    "crud",
+			doc:     map[string]interface{}{"users": "crud"},
+			want:    map[string]interface{}{"users": []interface{}{"create", "read", "update", "delete"}},
+			changed: true,
+		},
+		{
+			name:    "single letter next to grammar values",
+			doc:     map[string]interface{}{"media": "r", "users": "read"},
+			want:    map[string]interface{}{"media": []interface{}{"read"}, "users": "read"},
+			changed: true,
+		},
+		{
+			name:    "letters inside an array and a list",
+			doc:     map[string]interface{}{"media": []interface{}{"ru", "!delete"}, "users": "cr,update:own"},
+			want:    map[string]interface{}{"media": []interface{}{"read", "update", "!delete"}, "users": []interface{}{"create", "read", "update:own"}},
+			changed: true,
+		},
+		{
+			name:    "repeated letters",
+			doc:     map[string]interface{}{"users": "rr"},
+			want:    map[string]interface{}{"users": []interface{}{"read"}},
+			changed: true,
+		},
+		{
+			name: "grammar values are kept",
+			doc:  map[string]interface{}{"users": []interface{}{"*", "!delete"}, "*": "read", "settings": "all"},
+			want: map[string]interface{}{"users": []interface{}{"*", "!delete"}, "*": "read", "settings": "all"},
+		},
+		{
+			name: "invalid values are left for validation",
+			doc:  map[string]interface{}{"users": []interface{}{"crud", 1}, "media": 3},
+			want: map[string]interface{}{"users": []interface{}{"crud", 1}, "media": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := UpgradeLegacy(tt.doc)
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpgradeLegacy = %#v, want %#v", got, tt.want)
+			}
+			if err := Validate(got); tt.changed && err != nil {
+				t.Errorf("upgraded document is invalid: %v", err)
+			}
+		})
+	}
+
+	// The upgraded document grants what the letters meant
+	doc, _ := UpgradeLegacy(map[string]interface{}{"users": "crud"})
+	set, _ := ParseDocument(doc)
+	for _, action := range []string{"create", "read", "update", "delete"} {
+		if !set.Allows("users", action) {
+			t.Errorf("upgraded crud does not allow users:%s", action)
+		}
+	}
+}
+
+func TestGrantAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     map[string]interface{}
+		want    map[string]interface{}
+		changed bool
+	}{
+		{
+			name:    "allow rules are replaced",
+			doc:     map[string]interface{}{"users": []interface{}{"read", "update"}},
+			want:    map[string]interface{}{"*": []interface{}{"*"}},
+			changed: true,
+		},
+		{
+			name:    "deny rules are kept",
+			doc:     map[string]interface{}{"users": []interface{}{"read", "!delete"}, "media": "read, !update"},
+			want:    map[string]interface{}{"*": []interface{}{"*"}, "users": []interface{}{"!delete"}, "media": []interface{}{"!update"}},
+			changed: true,
+		},
+		{
+			name:    "wildcard deny is kept",
+			doc:     map[string]interface{}{"*": "!delete"},
+			want:    map[string]interface{}{"*": []interface{}{"*", "!delete"}},
+			changed: true,
+		},
+		{
+			name:    "already grants everything",
+			doc:     map[string]interface{}{"*": "*", "users": "!delete"},
+			want:    map[string]interface{}{"*": "*", "users": "!delete"},
+			changed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := GrantAll(tt.doc)
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GrantAll = %#v, want %#v", got, tt.want)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("granted document is invalid: %v", err)
+			}
+		})
+	}
+
+	// Deny rules still win over the granted wildcard
+	doc, _ := GrantAll(map[string]interface{}{"users": []interface{}{"read", "!delete"}})
+	set, _ := ParseDocument(doc)
+	if !set.Allows("media", "update") {
+		t.Error("granted document does not allow media:update")
+	}
+	if set.Allows("users", "delete") {
+		t.Error("granted document allows the denied users:delete")
+	}
+}
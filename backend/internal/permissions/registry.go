@@ -0,0 +1,132 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resource describes a resource that permissions can refer to
+type Resource struct {
+	Resource    string   `json:"resource"`
+	Description string   `json:"description"`
+	Actions     []string `json:"actions"`
+	Ownable     bool     `json:"ownable"` // Accepts the "own" scope; owners are defined in package authz
+}
+
+// Resources lists every resource and action known to the API.
+// Add an entry here when introducing a route guarded by middleware.PermissionRequired.
+var Resources = []Resource{
+	{Resource: "users", Description: "User accounts", Actions: []string{"create", "read", "update", "delete"}, Ownable: true},
+	{Resource: "roles", Description: "Roles and their permissions", Actions: []string{"create", "read", "update", "delete"}},
+	{Resource: "sessions", Description: "Device sessions of other users", Actions: []string{"read", "delete"}},
+	{Resource: "media", Description: "Uploaded files", Actions: []string{"create", "read", "update", "delete"}, Ownable: true},
+	{Resource: "settings", Description: "Application settings", Actions: []string{"read", "update"}},
+	{Resource: "audit_logs", Description: "Audit trail", Actions: []string{"read"}},
+	{Resource: "emails", Description: "Outgoing email queue", Actions: []string{"read", "update"}},
+}
+
+// Error describes a single problem in a permission document
+type Error struct {
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+}
+
+// DocumentError lists every problem found in a permission document
+type DocumentError struct {
+	Errors []Error
+}
+
+func (e *DocumentError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, pe := range e.Errors {
+		messages = append(messages, pe.Resource+": "+pe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Pair is a concrete resource/action combination
+type Pair struct {
+	Resource string
+	Action   string
+}
+
+// lookup returns a registered resource
+func lookup(resource string) (Resource, bool) {
+	for _, r := range Resources {
+		if r.Resource == resource {
+			return r, true
+		}
+	}
+	return Resource{}, false
+}
+
+// Expand lists the registered resource/action pairs a rule matches
+func Expand(rule Rule) []Pair {
+	var pairs []Pair
+	for _, r := range Resources {
+		if rule.Resource != Wildcard && rule.Resource != r.Resource {
+			continue
+		}
+		for _, action := range r.Actions {
+			if rule.Action == Wildcard || rule.Action == action {
+				pairs = append(pairs, Pair{Resource: r.Resource, Action: action})
+			}
+		}
+	}
+	return pairs
+}
+
+// Check reports rules that name an unregistered resource or an action the resource does not have
+func Check(set Set) []Error {
+	var problems []Error
+	for _, rule := range set {
+		if rule.Resource == Wildcard {
+			if rule.Action != Wildcard && len(Expand(rule)) == 0 {
+				problems = append(problems, Error{Resource: rule.Resource, Message: fmt.Sprintf("no resource has action %q", rule.Action)})
+			}
+			continue
+		}
+
+		resource, ok := lookup(rule.Resource)
+		if !ok {
+			problems = append(problems, Error{Resource: rule.Resource, Message: "unknown resource"})
+			continue
+		}
+		if rule.Action != Wildcard && !contains(resource.Actions, rule.Action) {
+			problems = append(problems, Error{
+				Resource: rule.Resource,
+				Message:  fmt.Sprintf("unknown action %q (allowed: %s, *)", rule.Action, strings.Join(resource.Actions, ", ")),
+			})
+		}
+		if rule.Scope == ScopeOwn && !resource.Ownable {
+			problems = append(problems, Error{Resource: rule.Resource, Message: "resource has no owner, so the \"own\" scope cannot be used"})
+		}
+	}
+	return problems
+}
+
+// Validate parses a role permission document and checks it against the registry.
+// It returns a *DocumentError listing every problem.
+func Validate(doc map[string]interface{}) error {
+	set, err := ParseDocument(doc)
+
+	var problems []Error
+	if docErr, ok := err.(*DocumentError); ok {
+		problems = docErr.Errors
+	}
+	problems = append(problems, Check(set)...)
+
+	if len(problems) > 0 {
+		return &DocumentError{Errors: problems}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Condition operators
+const (
+	OpEquals      = "eq"
+	OpNotEquals   = "neq"
+	OpIn          = "in"
+	OpNotIn       = "not_in"
+	OpContains    = "contains"
+	OpNotContains = "not_contains"
+	OpGreater     = "gt"
+	OpGreaterEq   = "gte"
+	OpLess        = "lt"
+	OpLessEq      = "lte"
+	OpBetween     = "between" // Inclusive; ranges like ["22:00", "06:00"] wrap around
+	OpCIDR        = "cidr"    // IP address within one of the networks
+	OpExists      = "exists"
+	OpNotExists   = "not_exists"
+)
+
+// Operators lists every condition operator
+var Operators = []string{
+	OpEquals, OpNotEquals, OpIn, OpNotIn, OpContains, OpNotContains,
+	OpGreater, OpGreaterEq, OpLess, OpLessEq, OpBetween, OpCIDR, OpExists, OpNotExists,
+}
+
+var (
+	errNotComparable    = errors.New("values are not comparable")
+	errMissingAttribute = errors.New("attribute is missing")
+)
+
+// compare applies an operator to an attribute value and the expected value.
+// Only exists and not_exists accept a missing (nil) attribute; every other operator
+// reports an error, so a deny policy on a missing attribute still applies.
+func compare(op string, actual, expected interface{}) (bool, error) {
+	switch op {
+	case OpExists:
+		return actual != nil, nil
+	case OpNotExists:
+		return actual == nil, nil
+	}
+	if actual == nil {
+		return false, errMissingAttribute
+	}
+
+	switch op {
+	case OpEquals:
+		return equal(actual, expected), nil
+	case OpNotEquals:
+		return !equal(actual, expected), nil
+	case OpIn, OpNotIn:
+		list, ok := toList(expected)
+		if !ok {
+			return false, fmt.Errorf("%s needs a list value", op)
+		}
+		found := anyEqual(actual, list)
+		return found == (op == OpIn), nil
+	case OpContains, OpNotContains:
+		list, ok := toList(actual)
+		if !ok {
+			return false, fmt.Errorf("%s needs a list attribute", op)
+		}
+		found := anyEqual(expected, list)
+		return found == (op == OpContains), nil
+	case OpGreater, OpGreaterEq, OpLess, OpLessEq:
+		c, err := order(actual, expected)
+		if err != nil {
+			return false, err
+		}
+		switch op {
+		case OpGreater:
+			return c > 0, nil
+		case OpGreaterEq:
+			return c >= 0, nil
+		case OpLess:
+			return c < 0, nil
+		default:
+			return c <= 0, nil
+		}
+	case OpBetween:
+		bounds, ok := toList(expected)
+		if !ok || len(bounds) != 2 {
+			return false, errors.New("between needs a [low, high] value")
+		}
+		low, err := order(bounds[0], bounds[1])
+		if err != nil {
+			return false, err
+		}
+		fromLow, err := order(actual, bounds[0])
+		if err != nil {
+			return false, err
+		}
+		toHigh, err := order(actual, bounds[1])
+		if err != nil {
+			return false, err
+		}
+		if low > 0 {
+			// Wrapping range such as ["22:00", "06:00"]
+			return fromLow >= 0 || toHigh <= 0, nil
+		}
+		return fromLow >= 0 && toHigh <= 0, nil
+	case OpCIDR:
+		return inNetworks(actual, expected)
+	default:
+		return false, fmt.Errorf("unknown operator %q", op)
+	}
+}
+
+// equal compares scalars, treating numbers of any type alike
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func anyEqual(value interface{}, list []interface{}) bool {
+	// A list attribute (e.g. subject.roles) is in a list when any element is
+	if values, ok := toList(value); ok {
+		for _, v := range values {
+			if anyEqual(v, list) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, item := range list {
+		if equal(value, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// order compares two numbers or two strings, returning -1, 0 or 1
+func order(a, b interface{}) (int, error) {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		if !ok {
+			return 0, errNotComparable
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	x, ok := a.(string)
+	if !ok {
+		return 0, errNotComparable
+	}
+	y, ok := b.(string)
+	if !ok {
+		return 0, errNotComparable
+	}
+	switch {
+	case x < y:
+		return -1, nil
+	case x > y:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func inNetworks(actual, expected interface{}) (bool, error) {
+	s, ok := actual.(string)
+	if !ok {
+		return false, errors.New("cidr needs an IP address attribute")
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false, fmt.Errorf("invalid IP address %q", s)
+	}
+
+	networks, ok := toList(expected)
+	if !ok {
+		networks = []interface{}{expected}
+	}
+	for _, n := range networks {
+		cidr, _ := n.(string)
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return false, fmt.Errorf("invalid network %v", n)
+		}
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case json.Number:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toList(v interface{}) ([]interface{}, bool) {
+	switch l := v.(type) {
+	case []interface{}:
+		return l, true
+	case []string:
+		list := make([]interface{}, len(l))
+		for i, s := range l {
+			list[i] = s
+		}
+		return list, true
+	}
+	return nil, false
+}
@@ -0,0 +1,231 @@
+// Package policy evaluates attribute-based authorization policies on top of role permissions.
+//
+// A request is decided as follows:
+//
+//  1. A matching deny policy refuses it.
+//  2. Otherwise role permissions (package authz) allow it. An "own" permission only
+//     counts when the resource attribute owner_id is the user's ID.
+//  3. Otherwise a matching allow policy allows it.
+//  4. Otherwise it is refused.
+//
+// A policy matches when its resources and actions include the request's (or "*") and
+// every condition holds. A condition that cannot be evaluated (a missing attribute or a
+// type mismatch) fails an allow policy but holds for a deny policy, so errors never
+// open access. Conditions read these attributes:
+//
+//	subject.id, subject.email, subject.name, subject.role, subject.roles, subject.is_admin,
+//	subject.email_verified, subject.mfa_enabled, subject.locale
+//	resource.type and resource.<name> for every attribute of the resource (e.g. resource.roles)
+//	action
+//	context.time ("15:04"), context.hour, context.weekday ("mon"), context.date ("2006-01-02"),
+//	context.ip, context.method, context.path
+package policy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+)
+
+// Context describes the request being authorized
+type Context struct {
+	Time      time.Time
+	IPAddress string
+	Method    string
+	Path      string
+}
+
+// Request is an authorization question
+type Request struct {
+	User       *models.User
+	Subject    *authz.Subject
+	Resource   string
+	Action     string
+	Attributes map[string]interface{} // Resource attributes, available as resource.<name>
+	Context    Context
+}
+
+// ConditionResult is the outcome of one condition
+type ConditionResult struct {
+	models.PolicyCondition
+	Actual interface{} `json:"actual"`
+	Passed bool        `json:"passed"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// PolicyResult is the outcome of one policy
+type PolicyResult struct {
+	ID         uuid.UUID         `json:"id"`
+	Name       string            `json:"name"`
+	Effect     string            `json:"effect"`
+	Applies    bool              `json:"applies"` // Resource and action match
+	Matched    bool              `json:"matched"` // Applies and every condition holds
+	Conditions []ConditionResult `json:"conditions,omitempty"`
+}
+
+// Decision is the answer to a Request, with the reasoning behind it
+type Decision struct {
+	Allowed    bool           `json:"allowed"`
+	Reason     string         `json:"reason"`
+	RoleAccess string         `json:"role_access"` // none, own or any
+	Policies   []PolicyResult `json:"policies"`
+}
+
+// Evaluate decides a request against role permissions and the given policies.
+// Inactive policies are skipped.
+func Evaluate(policies []models.Policy, req Request) Decision {
+	attrs := Attributes(req)
+
+	decision := Decision{Policies: make([]PolicyResult, 0, len(policies))}
+	var denied, allowed *PolicyResult
+	for _, p := range policies {
+		if !p.IsActive {
+			continue
+		}
+
+		result := evaluatePolicy(p, req, attrs)
+		decision.Policies = append(decision.Policies, result)
+		if !result.Matched {
+			continue
+		}
+
+		r := result
+		if p.Effect == models.PolicyEffectDeny && denied == nil {
+			denied = &r
+		}
+		if p.Effect == models.PolicyEffectAllow && allowed == nil {
+			allowed = &r
+		}
+	}
+
+	scope := permissions.ScopeNone
+	if req.Subject != nil {
+		scope = req.Subject.Access(req.Resource, req.Action)
+	}
+	decision.RoleAccess = scope.String()
+
+	roleAllowed := scope == permissions.ScopeAny
+	if scope == permissions.ScopeOwn && req.User != nil {
+		ownerID, ok := req.Attributes["owner_id"]
+		roleAllowed = ok && equal(ownerID, req.User.ID.String())
+	}
+
+	switch {
+	case denied != nil:
+		decision.Reason = fmt.Sprintf("denied by policy %q", denied.Name)
+	case roleAllowed:
+		decision.Allowed = true
+		decision.Reason = "allowed by role permissions"
+	case allowed != nil:
+		decision.Allowed = true
+		decision.Reason = fmt.Sprintf("allowed by policy %q", allowed.Name)
+	default:
+		decision.Reason = "no role permission or policy allows this action"
+	}
+	return decision
+}
+
+func evaluatePolicy(p models.Policy, req Request, attrs map[string]interface{}) PolicyResult {
+	result := PolicyResult{ID: p.ID, Name: p.Name, Effect: p.Effect}
+	result.Applies = matchesName(p.Resources, req.Resource) && matchesName(p.Actions, req.Action)
+	if !result.Applies {
+		return result
+	}
+
+	result.Matched = true
+	for _, condition := range p.Conditions {
+		cr := evaluateCondition(condition, attrs)
+		result.Conditions = append(result.Conditions, cr)
+		// Deny policies fail closed
+		if cr.Error != "" && p.Effect == models.PolicyEffectDeny {
+			continue
+		}
+		if !cr.Passed {
+			result.Matched = false
+		}
+	}
+	return result
+}
+
+func matchesName(list models.StringList, name string) bool {
+	return list.Contains(permissions.Wildcard) || list.Contains(name)
+}
+
+func evaluateCondition(c models.PolicyCondition, attrs map[string]interface{}) ConditionResult {
+	result := ConditionResult{PolicyCondition: c}
+	result.Actual = attrs[c.Attribute]
+
+	expected := c.Value
+	if c.ValueFrom != "" {
+		var ok bool
+		if expected, ok = attrs[c.ValueFrom]; !ok || expected == nil {
+			result.Error = fmt.Sprintf("%s: %v", c.ValueFrom, errMissingAttribute)
+			return result
+		}
+	}
+
+	passed, err := compare(c.Operator, result.Actual, expected)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Passed = passed
+	return result
+}
+
+// Attributes flattens a request into the attributes conditions can read
+func Attributes(req Request) map[string]interface{} {
+	attrs := map[string]interface{}{
+		"action":        req.Action,
+		"resource.type": req.Resource,
+	}
+
+	if user := req.User; user != nil {
+		attrs["subject.id"] = user.ID.String()
+		attrs["subject.email"] = user.Email
+		attrs["subject.name"] = user.Name
+		attrs["subject.role"] = user.RoleName()
+		attrs["subject.email_verified"] = user.EmailVerified
+		attrs["subject.mfa_enabled"] = user.TOTPEnabled
+		attrs["subject.locale"] = user.Locale
+	}
+	if subject := req.Subject; subject != nil {
+		attrs["subject.roles"] = subject.RoleNames()
+		attrs["subject.is_admin"] = subject.IsAdmin()
+	}
+
+	for name, value := range req.Attributes {
+		attrs["resource."+name] = value
+	}
+
+	now := req.Context.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.In(location())
+	attrs["context.time"] = now.Format("15:04")
+	attrs["context.hour"] = now.Hour()
+	attrs["context.weekday"] = strings.ToLower(now.Weekday().String()[:3])
+	attrs["context.date"] = now.Format("2006-01-02")
+	attrs["context.ip"] = req.Context.IPAddress
+	attrs["context.method"] = req.Context.Method
+	attrs["context.path"] = req.Context.Path
+
+	return attrs
+}
+
+// location returns the time zone for context.* time attributes (POLICY_TIMEZONE, default server local time)
+func location() *time.Location {
+	if name := os.Getenv("POLICY_TIMEZONE"); name != "" {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
@@ -0,0 +1,184 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/models"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       string
+		actual   interface{}
+		expected interface{}
+		want     bool
+		wantErr  bool
+	}{
+		{name: "eq strings", op: OpEquals, actual: "a", expected: "a", want: true},
+		{name: "eq numbers of different types", op: OpEquals, actual: 3, expected: json.Number("3"), want: true},
+		{name: "eq mismatch", op: OpEquals, actual: "a", expected: "b", want: false},
+		{name: "eq missing", op: OpEquals, actual: nil, expected: "a", wantErr: true},
+
+		{name: "neq", op: OpNotEquals, actual: "a", expected: "b", want: true},
+		{name: "neq equal", op: OpNotEquals, actual: true, expected: true, want: false},
+		{name: "neq missing", op: OpNotEquals, actual: nil, expected: "a", wantErr: true},
+
+		{name: "in", op: OpIn, actual: "b", expected: []interface{}{"a", "b"}, want: true},
+		{name: "in list attribute", op: OpIn, actual: []string{"x", "b"}, expected: []interface{}{"a", "b"}, want: true},
+		{name: "in absent", op: OpIn, actual: "c", expected: []interface{}{"a", "b"}, want: false},
+		{name: "in scalar value", op: OpIn, actual: "a", expected: "a", wantErr: true},
+		{name: "in missing", op: OpIn, actual: nil, expected: []interface{}{"a"}, wantErr: true},
+
+		{name: "not_in", op: OpNotIn, actual: "c", expected: []interface{}{"a", "b"}, want: true},
+		{name: "not_in present", op: OpNotIn, actual: "a", expected: []interface{}{"a", "b"}, want: false},
+		{name: "not_in missing", op: OpNotIn, actual: nil, expected: []interface{}{"a"}, wantErr: true},
+
+		{name: "contains", op: OpContains, actual: []string{"editor", "viewer"}, expected: "editor", want: true},
+		{name: "contains absent", op: OpContains, actual: []string{"viewer"}, expected: "editor", want: false},
+		{name: "contains scalar attribute", op: OpContains, actual: "editor", expected: "editor", wantErr: true},
+		{name: "contains missing", op: OpContains, actual: nil, expected: "editor", wantErr: true},
+
+		{name: "not_contains", op: OpNotContains, actual: []interface{}{"viewer"}, expected: "editor", want: true},
+		{name: "not_contains present", op: OpNotContains, actual: []interface{}{"editor"}, expected: "editor", want: false},
+		{name: "not_contains missing", op: OpNotContains, actual: nil, expected: "editor", wantErr: true},
+
+		{name: "gt", op: OpGreater, actual: 5, expected: 3, want: true},
+		{name: "gt equal", op: OpGreater, actual: 3, expected: 3, want: false},
+		{name: "gt strings", op: OpGreater, actual: "18:00", expected: "09:00", want: true},
+		{name: "gt mixed types", op: OpGreater, actual: 5, expected: "3", wantErr: true},
+		{name: "gt missing", op: OpGreater, actual: nil, expected: 3, wantErr: true},
+
+		{name: "gte", op: OpGreaterEq, actual: 3, expected: 3.0, want: true},
+		{name: "gte less", op: OpGreaterEq, actual: 2, expected: 3, want: false},
+		{name: "gte missing", op: OpGreaterEq, actual: nil, expected: 3, wantErr: true},
+
+		{name: "lt", op: OpLess, actual: 2, expected: 3, want: true},
+		{name: "lt equal", op: OpLess, actual: 3, expected: 3, want: false},
+		{name: "lt missing", op: OpLess, actual: nil, expected: 3, wantErr: true},
+
+		{name: "lte", op: OpLessEq, actual: 3, expected: 3, want: true},
+		{name: "lte greater", op: OpLessEq, actual: 4, expected: 3, want: false},
+		{name: "lte bool", op: OpLessEq, actual: true, expected: 3, wantErr: true},
+		{name: "lte missing", op: OpLessEq, actual: nil, expected: 3, wantErr: true},
+
+		{name: "between", op: OpBetween, actual: "12:00", expected: []interface{}{"09:00", "17:00"}, want: true},
+		{name: "between bounds are inclusive", op: OpBetween, actual: "17:00", expected: []interface{}{"09:00", "17:00"}, want: true},
+		{name: "between outside", op: OpBetween, actual: "18:00", expected: []interface{}{"09:00", "17:00"}, want: false},
+		{name: "between wrapping", op: OpBetween, actual: "23:00", expected: []interface{}{"22:00", "06:00"}, want: true},
+		{name: "between wrapping outside", op: OpBetween, actual: "12:00", expected: []interface{}{"22:00", "06:00"}, want: false},
+		{name: "between one bound", op: OpBetween, actual: "12:00", expected: []interface{}{"09:00"}, wantErr: true},
+		{name: "between missing", op: OpBetween, actual: nil, expected: []interface{}{"09:00", "17:00"}, wantErr: true},
+
+		{name: "cidr", op: OpCIDR, actual: "10.1.2.3", expected: "10.0.0.0/8", want: true},
+		{name: "cidr list", op: OpCIDR, actual: "192.168.1.1", expected: []interface{}{"10.0.0.0/8", "192.168.0.0/16"}, want: true},
+		{name: "cidr outside", op: OpCIDR, actual: "8.8.8.8", expected: "10.0.0.0/8", want: false},
+		{name: "cidr invalid network", op: OpCIDR, actual: "10.1.2.3", expected: "10.0.0.0", wantErr: true},
+		{name: "cidr invalid address", op: OpCIDR, actual: "", expected: "10.0.0.0/8", wantErr: true},
+		{name: "cidr non-string attribute", op: OpCIDR, actual: 10, expected: "10.0.0.0/8", wantErr: true},
+		{name: "cidr missing", op: OpCIDR, actual: nil, expected: "10.0.0.0/8", wantErr: true},
+
+		{name: "exists", op: OpExists, actual: "a", want: true},
+		{name: "exists missing", op: OpExists, actual: nil, want: false},
+		{name: "not_exists", op: OpNotExists, actual: nil, want: true},
+		{name: "not_exists present", op: OpNotExists, actual: false, want: false},
+
+		{name: "unknown operator", op: "like", actual: "a", expected: "a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compare(tt.op, tt.actual, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compare(%s, %#v, %#v) error = %v, wantErr %v", tt.op, tt.actual, tt.expected, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("compare(%s, %#v, %#v) = %v, want %v", tt.op, tt.actual, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateConditionErrors(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "user@example.com"}
+	request := Request{
+		User:       user,
+		Resource:   "media",
+		Action:     "delete",
+		Attributes: map[string]interface{}{"status": "draft"},
+		Context:    Context{Time: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC), IPAddress: "10.1.2.3"},
+	}
+
+	policy := func(effect string, conditions ...models.PolicyCondition) models.Policy {
+		return models.Policy{
+			ID:         uuid.New(),
+			Name:       effect + " policy",
+			Effect:     effect,
+			Resources:  models.StringList{"media"},
+			Actions:    models.StringList{"*"},
+			Conditions: conditions,
+			IsActive:   true,
+		}
+	}
+	missing := models.PolicyCondition{Attribute: "resource.owner_id", Operator: OpEquals, Value: "x"}
+	missingNet := models.PolicyCondition{Attribute: "resource.uploader_ip", Operator: OpCIDR, Value: "10.0.0.0/8"}
+	missingFrom := models.PolicyCondition{Attribute: "subject.id", Operator: OpEquals, ValueFrom: "resource.owner_id"}
+	mismatch := models.PolicyCondition{Attribute: "resource.status", Operator: OpGreater, Value: 3}
+	holds := models.PolicyCondition{Attribute: "resource.status", Operator: OpEquals, Value: "draft"}
+	fails := models.PolicyCondition{Attribute: "resource.status", Operator: OpEquals, Value: "published"}
+
+	tests := []struct {
+		name     string
+		policies []models.Policy
+		allowed  bool
+	}{
+		{name: "allow policy with a holding condition", policies: []models.Policy{policy(models.PolicyEffectAllow, holds)}, allowed: true},
+		{name: "allow policy on a missing attribute", policies: []models.Policy{policy(models.PolicyEffectAllow, missing)}, allowed: false},
+		{name: "allow policy on a missing network attribute", policies: []models.Policy{policy(models.PolicyEffectAllow, missingNet)}, allowed: false},
+		{name: "allow policy on a missing value_from", policies: []models.Policy{policy(models.PolicyEffectAllow, missingFrom)}, allowed: false},
+		{name: "allow policy on a type mismatch", policies: []models.Policy{policy(models.PolicyEffectAllow, mismatch)}, allowed: false},
+		{name: "allow policy with a failing condition", policies: []models.Policy{policy(models.PolicyEffectAllow, fails)}, allowed: false},
+		{
+			name:     "deny policy on a missing attribute",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, missing)},
+			allowed:  false,
+		},
+		{
+			name:     "deny policy on a missing network attribute",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, missingNet)},
+			allowed:  false,
+		},
+		{
+			name:     "deny policy on a missing value_from",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, missingFrom)},
+			allowed:  false,
+		},
+		{
+			name:     "deny policy on a type mismatch",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, mismatch)},
+			allowed:  false,
+		},
+		{
+			name:     "deny policy with a failing condition",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, fails)},
+			allowed:  true,
+		},
+		{
+			name:     "deny policy with an error and a failing condition",
+			policies: []models.Policy{policy(models.PolicyEffectAllow, holds), policy(models.PolicyEffectDeny, missing, fails)},
+			allowed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := Evaluate(tt.policies, request)
+			if decision.Allowed != tt.allowed {
+				t.Errorf("Allowed = %v, want %v (%s)", decision.Allowed, tt.allowed, decision.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+)
+
+// subjectAttributes and contextAttributes list the attributes Attributes provides
+var (
+	subjectAttributes = []string{
+		"id", "email", "name", "role", "roles", "is_admin", "email_verified", "mfa_enabled", "locale",
+	}
+	contextAttributes = []string{"time", "hour", "weekday", "date", "ip", "method", "path"}
+)
+
+// FieldError describes a single problem in a policy
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every problem found in a policy
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Field+": "+fe.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks a policy before it is saved or dry-run. It returns a *ValidationError.
+func Validate(p *models.Policy) error {
+	var problems []FieldError
+	add := func(field, format string, args ...interface{}) {
+		problems = append(problems, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(p.Name) == "" {
+		add("name", "is required")
+	}
+	if p.Effect != models.PolicyEffectAllow && p.Effect != models.PolicyEffectDeny {
+		add("effect", "must be %q or %q", models.PolicyEffectAllow, models.PolicyEffectDeny)
+	}
+
+	if len(p.Resources) == 0 {
+		add("resources", "at least one resource is required")
+	}
+	var actions []string
+	for _, resource := range p.Resources {
+		if resource == permissions.Wildcard {
+			actions = append(actions, permissions.Wildcard)
+			continue
+		}
+		found := false
+		for _, r := range permissions.Resources {
+			if r.Resource == resource {
+				actions = append(actions, r.Actions...)
+				found = true
+			}
+		}
+		if !found {
+			add("resources", "unknown resource %q", resource)
+		}
+	}
+
+	if len(p.Actions) == 0 {
+		add("actions", "at least one action is required")
+	}
+	for _, action := range p.Actions {
+		if action != permissions.Wildcard && !contains(actions, permissions.Wildcard) && !contains(actions, action) {
+			add("actions", "unknown action %q for the listed resources", action)
+		}
+	}
+
+	for i, c := range p.Conditions {
+		field := fmt.Sprintf("conditions[%d]", i)
+		if err := validAttribute(c.Attribute); err != nil {
+			add(field+".attribute", "%v", err)
+		}
+		if c.ValueFrom != "" {
+			if err := validAttribute(c.ValueFrom); err != nil {
+				add(field+".value_from", "%v", err)
+			}
+		}
+		if !contains(Operators, c.Operator) {
+			add(field+".operator", "unknown operator %q (allowed: %s)", c.Operator, strings.Join(Operators, ", "))
+			continue
+		}
+		if msg := checkValue(c); msg != "" {
+			add(field+".value", "%s", msg)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Errors: problems}
+	}
+	return nil
+}
+
+func validAttribute(attribute string) error {
+	if attribute == "action" {
+		return nil
+	}
+
+	prefix, name, ok := strings.Cut(attribute, ".")
+	if !ok || name == "" {
+		return fmt.Errorf("unknown attribute %q", attribute)
+	}
+	switch prefix {
+	case "resource":
+		return nil
+	case "subject":
+		if contains(subjectAttributes, name) {
+			return nil
+		}
+	case "context":
+		if contains(contextAttributes, name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown attribute %q", attribute)
+}
+
+// checkValue reports whether a condition's value has the shape its operator needs
+func checkValue(c models.PolicyCondition) string {
+	switch c.Operator {
+	case OpExists, OpNotExists:
+		return ""
+	}
+	if c.ValueFrom != "" {
+		return ""
+	}
+	if c.Value == nil {
+		return "is required (or set value_from)"
+	}
+
+	switch c.Operator {
+	case OpIn, OpNotIn:
+		if _, ok := toList(c.Value); !ok {
+			return "must be a list"
+		}
+	case OpBetween:
+		if bounds, ok := toList(c.Value); !ok || len(bounds) != 2 {
+			return "must be a [low, high] list"
+		}
+	case OpCIDR:
+		networks, ok := toList(c.Value)
+		if !ok {
+			networks = []interface{}{c.Value}
+		}
+		for _, n := range networks {
+			cidr, _ := n.(string)
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Sprintf("invalid network %v", n)
+			}
+		}
+	}
+	return ""
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -12,23 +12,123 @@ func SetupRoutes(app *fiber.App) {
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler()
+	emailHandler := handlers.NewEmailHandler()
+	mfaHandler := handlers.NewMFAHandler()
+	passkeyHandler := handlers.NewPasskeyHandler()
+	oauthHandler := handlers.NewOAuthHandler()
+	tokenHandler := handlers.NewTokenHandler()
+	userHandler := handlers.NewUserHandler()
+	roleHandler := handlers.NewRoleHandler()
+	policyHandler := handlers.NewPolicyHandler()
+	cacheHandler := handlers.NewCacheHandler()
+	impersonationHandler := handlers.NewImpersonationHandler()
+	sessionHandler := handlers.NewSessionHandler()
+	jwksHandler := handlers.NewJWKSHandler()
+
+	// Token verification keys for other services
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
+	// Stricter per-IP limit for endpoints that accept credentials or send email
+	authLimit := middleware.AuthRateLimit()
 
 	// Public routes
 	auth := api.Group("/auth")
-	auth.Post("/register", authHandler.Register)
-	auth.Post("/login", authHandler.Login)
+	auth.Post("/register", authLimit, authHandler.Register)
+	auth.Post("/login", authLimit, authHandler.Login)
 	auth.Post("/refresh", authHandler.RefreshToken)
+	auth.Post("/logout", authHandler.Logout)
+	auth.Post("/forgot-password", authLimit, authHandler.ForgotPassword)
+	auth.Post("/reset-password", authLimit, authHandler.ResetPassword)
+	auth.Get("/verify-email", authHandler.VerifyEmail)
+	auth.Post("/verify-email", authHandler.VerifyEmail)
+	auth.Post("/resend-verification", authLimit, authHandler.ResendVerification)
 
 	// Protected routes (require authentication)
 	auth.Get("/me", middleware.AuthRequired, authHandler.GetProfile)
+	auth.Put("/me", middleware.AuthRequired, middleware.SessionRequired, authHandler.UpdateProfile)
+	auth.Post("/logout-all", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, authHandler.LogoutAll)
+	auth.Post("/change-password", authLimit, middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, authHandler.ChangePassword)
+	auth.Post("/change-email", authLimit, middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, authHandler.RequestEmailChange)
+	auth.Post("/change-email/confirm", authHandler.ConfirmEmailChange)
+	auth.Post("/impersonation/stop", middleware.AuthRequired, impersonationHandler.StopCurrentImpersonation)
+
+	// Two-factor authentication
+	mfa := auth.Group("/mfa")
+	mfa.Post("/verify", authLimit, mfaHandler.VerifyLogin)
+	mfa.Post("/totp/setup", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, mfaHandler.SetupTOTP)
+	mfa.Post("/totp/confirm", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, mfaHandler.ConfirmTOTP)
+	mfa.Post("/totp/disable", authLimit, middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, mfaHandler.DisableTOTP)
+	mfa.Post("/recovery-codes", authLimit, middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, mfaHandler.RegenerateRecoveryCodes)
+
+	// Passkeys (WebAuthn)
+	passkeys := auth.Group("/passkeys")
+	passkeys.Post("/login/begin", authLimit, passkeyHandler.BeginLogin)
+	passkeys.Post("/login/finish", authLimit, passkeyHandler.FinishLogin)
+	passkeys.Get("/", middleware.AuthRequired, passkeyHandler.ListPasskeys)
+	passkeys.Post("/register/begin", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, passkeyHandler.BeginRegistration)
+	passkeys.Post("/register/finish", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, passkeyHandler.FinishRegistration)
+	passkeys.Delete("/:id", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating, passkeyHandler.DeletePasskey)
+
+	// Signed-in devices
+	sessions := auth.Group("/sessions", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating)
+	sessions.Get("/", sessionHandler.ListSessions)
+	sessions.Delete("/:id", sessionHandler.RevokeSession)
+
+	// Personal access tokens (managed from the owner's own login session only)
+	tokens := auth.Group("/tokens", middleware.AuthRequired, middleware.SessionRequired, middleware.NotImpersonating)
+	tokens.Get("/", tokenHandler.ListTokens)
+	tokens.Post("/", tokenHandler.CreateToken)
+	tokens.Delete("/:id", tokenHandler.RevokeToken)
+
+	// External login (OIDC / OAuth2)
+	oauth := auth.Group("/oauth")
+	oauth.Get("/providers", oauthHandler.ListProviders)
+	oauth.Get("/:provider/authorize", oauthHandler.Authorize)
+	oauth.Post("/:provider/callback", authLimit, oauthHandler.Callback)
+
+	// User directory: "users:read" reads every account, "users:read:own" only the caller's
+	users := api.Group("/users", middleware.AuthRequired, middleware.PermissionRequired("users", "read"))
+	users.Get("/", userHandler.ListUsers)
+	users.Get("/:id", userHandler.GetUser)
 
 	// Admin routes (require admin role)
 	admin := api.Group("/admin", middleware.AuthRequired, middleware.AdminOnly)
-	_ = admin // TODO: Add admin routes
+	admin.Get("/emails", emailHandler.ListEmails)
+	admin.Get("/emails/:id", emailHandler.GetEmail)
+	admin.Post("/emails/:id/retry", emailHandler.RetryEmail)
+	admin.Get("/roles", roleHandler.ListRoles)
+	admin.Post("/roles", roleHandler.CreateRole)
+	admin.Get("/roles/:id", roleHandler.GetRole)
+	admin.Put("/roles/:id", roleHandler.UpdateRole)
+	admin.Delete("/roles/:id", roleHandler.DeleteRole)
+	admin.Get("/permissions", roleHandler.ListPermissions)
+	admin.Get("/policies", policyHandler.ListPolicies)
+	admin.Post("/policies", policyHandler.CreatePolicy)
+	admin.Post("/policies/explain", policyHandler.ExplainPolicy)
+	admin.Get("/policies/:id", policyHandler.GetPolicy)
+	admin.Put("/policies/:id", policyHandler.UpdatePolicy)
+	admin.Delete("/policies/:id", policyHandler.DeletePolicy)
+	admin.Get("/cache/auth", cacheHandler.GetAuthCacheStats)
+	admin.Delete("/cache/auth", cacheHandler.ClearAuthCache)
+
+	admin.Get("/users", userHandler.ListUsers)
+	admin.Post("/users", userHandler.CreateUser)
+	admin.Get("/users/:id", userHandler.GetUser)
+	admin.Put("/users/:id", userHandler.UpdateUser)
+	admin.Post("/users/:id/deactivate", userHandler.DeactivateUser)
+	admin.Post("/users/:id/reactivate", userHandler.ReactivateUser)
+	admin.Put("/users/:id/role", userHandler.AssignRole)
+	admin.Put("/users/:id/roles", userHandler.SetUserRoles)
+	admin.Post("/users/:id/reset-password", userHandler.ForcePasswordReset)
+	admin.Post("/users/:id/unlock", userHandler.UnlockUser)
+	admin.Post("/users/:id/impersonate", middleware.SessionRequired, impersonationHandler.StartImpersonation)
+	admin.Get("/users/:id/sessions", sessionHandler.ListUserSessions)
+	admin.Delete("/users/:id/sessions/:sessionId", sessionHandler.RevokeUserSession)
+	admin.Get("/impersonations", impersonationHandler.ListImpersonations)
+	admin.Post("/impersonations/:id/stop", impersonationHandler.StopImpersonation)
 
 	// TODO: Add more route groups:
 	// - /api/v1/public/* - Public endpoints (no auth required)
-	// - /api/v1/users/* - User management
 	// - /api/v1/upload/* - File upload
 	// - Domain-specific routes
 }
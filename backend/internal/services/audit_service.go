@@ -0,0 +1,21 @@
+package services
+
+import (
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuditService struct{}
+
+func NewAuditService() *AuditService {
+	return &AuditService{}
+}
+
+// Log records an audit log entry, inside tx when given
+func (s *AuditService) Log(tx *gorm.DB, entry *models.AuditLog) error {
+	if tx == nil {
+		tx = config.DB
+	}
+	return tx.Create(entry).Error
+}
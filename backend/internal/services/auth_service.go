@@ -1,27 +1,84 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
 	"github.com/your-org/go-next-template/internal/config"
 	"github.com/your-org/go-next-template/internal/models"
 	"github.com/your-org/go-next-template/pkg/utils"
+	"gorm.io/gorm"
 )
 
-type AuthService struct{}
+var (
+	// ErrEmailNotVerified is returned by Login when email verification is required
+	ErrEmailNotVerified = errors.New("email not verified")
+
+	errRefreshTokenReuse        = errors.New("refresh token reuse detected")
+	errInvalidResetToken        = errors.New("invalid or expired reset token")
+	errInvalidVerificationToken = errors.New("invalid or expired verification token")
+	errInvalidEmailChangeToken  = errors.New("invalid or expired email change token")
+	errWrongPassword            = errors.New("current password is incorrect")
+	errEmailTaken               = errors.New("email already exists")
+)
+
+// ClientInfo describes the client a request originates from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type AuthService struct {
+	auditService   *AuditService
+	emailOutbox    *EmailOutboxService
+	mfaService     *MFAService
+	passkeys       *PasskeyService
+	oauthService   *OAuthService
+	throttle       *LoginThrottleService
+	passwordPolicy *PasswordPolicy
+}
 
 func NewAuthService() *AuthService {
-	return &AuthService{}
+	return &AuthService{
+		auditService:   NewAuditService(),
+		emailOutbox:    NewEmailOutboxService(),
+		mfaService:     NewMFAService(),
+		passkeys:       NewPasskeyService(),
+		oauthService:   NewOAuthService(),
+		throttle:       NewLoginThrottleService(),
+		passwordPolicy: NewPasswordPolicy(),
+	}
 }
 
 // Register creates a new user
-func (s *AuthService) Register(email, password, name string) (*models.User, error) {
+func (s *AuthService) Register(email, password, name, locale string) (*models.User, error) {
+	// Emails are stored lower-cased and compared case-insensitively, so a case variant
+	// cannot register a second account
+	email = normalizeEmail(email)
+
 	// Check if user exists
 	var existingUser models.User
-	if err := config.DB.Where("email = ?", email).First(&existingUser).Error; err == nil {
-		return nil, errors.New("email already exists")
+	if err := config.DB.Where("LOWER(email) = LOWER(?)", email).First(&existingUser).Error; err == nil {
+		return nil, errEmailTaken
+	}
+
+	user := models.User{
+		Email:  email,
+		Name:   name,
+		Locale: locale,
+	}
+
+	// Check password policy
+	if err := s.passwordPolicy.Validate(password, &user); err != nil {
+		return nil, err
 	}
 
 	// Hash password
@@ -29,106 +86,906 @@ func (s *AuthService) Register(email, password, name string) (*models.User, erro
 	if err != nil {
 		return nil, err
 	}
+	user.PasswordHash = hashedPassword
 
 	// Create user
-	user := models.User{
-		Email:        email,
-		PasswordHash: hashedPassword,
-		Name:         name,
-	}
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		if err := s.passwordPolicy.RecordPassword(tx, user.ID, hashedPassword); err != nil {
+			return err
+		}
 
-	if err := config.DB.Create(&user).Error; err != nil {
+		return s.issueEmailVerification(tx, &user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
+// LoginResult is returned by a successful login. When MFARequired is set, no session
+// has been created yet and MFAToken must be exchanged via CompleteMFALogin.
+type LoginResult struct {
+	AccessToken  string       `json:"access_token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	User         *models.User `json:"user,omitempty"`
+	MFARequired  bool         `json:"mfa_required,omitempty"`
+	MFAToken     string       `json:"mfa_token,omitempty"`
+}
+
 // Login authenticates a user
-func (s *AuthService) Login(email, password string) (string, string, *models.User, error) {
+func (s *AuthService) Login(email, password string, client ClientInfo) (*LoginResult, error) {
+	// Refuse attempts that arrive before the progressive delay has passed. The attempt
+	// counts as a failure unless the password turns out to be correct.
+	attempt, err := s.throttle.Begin(email, client)
+	if err != nil {
+		return nil, err
+	}
+
 	// Find user
 	var user models.User
-	if err := config.DB.Preload("Role").Where("email = ?", email).First(&user).Error; err != nil {
-		return "", "", nil, errors.New("invalid credentials")
+	if err := config.DB.Preload("Role").Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		attempt.Fail(nil)
+		return nil, errors.New("invalid credentials")
+	}
+
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return nil, err
 	}
 
 	// Check if account is active
 	if !user.IsActive {
-		return "", "", nil, errors.New("account is disabled")
+		return nil, errors.New("account is disabled")
 	}
 
 	// Verify password
 	if !utils.CheckPasswordHash(password, user.PasswordHash) {
-		return "", "", nil, errors.New("invalid credentials")
+		attempt.Fail(&user)
+		return nil, errors.New("invalid credentials")
+	}
+	attempt.Release()
+	// Success is recorded once the session starts: a correct password alone must not
+	// reset the failures counted against the second factor
+
+	// Upgrade hashes made with an older algorithm or parameters while we have the plaintext
+	if utils.PasswordNeedsRehash(user.PasswordHash) {
+		s.rehashPassword(&user, password)
+	}
+
+	// Check if email is verified (only when required)
+	if config.RequireEmailVerification() && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.completeLogin(&user, client)
+}
+
+// CompleteMFALogin finishes a login with the MFA token from Login and a TOTP or recovery code
+func (s *AuthService) CompleteMFALogin(mfaToken, code string, client ClientInfo) (*LoginResult, error) {
+	userID, err := utils.VerifyMFAToken(mfaToken)
+	if err != nil {
+		return nil, errors.New("invalid or expired MFA token")
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Code guesses count towards the same throttle and lockout as passwords
+	attempt, err := s.throttle.Begin(user.Email, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return nil, err
 	}
 
-	// Generate tokens
-	role := ""
-	if user.Role != nil {
-		role = user.Role.Name
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, role)
+	if err := s.mfaService.VerifyCode(&user, code); err != nil {
+		attempt.Fail(&user)
+		return nil, err
+	}
+	attempt.Release()
+
+	return s.startSession(&user, client)
+}
+
+// LoginWithProvider finishes an external (OIDC/OAuth2) login
+func (s *AuthService) LoginWithProvider(ctx context.Context, provider, code, state, binding string, client ClientInfo) (*LoginResult, error) {
+	user, err := s.oauthService.Authenticate(ctx, provider, code, state, binding)
 	if err != nil {
-		return "", "", nil, err
+		return nil, err
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
+	}
+
+	if config.RequireEmailVerification() && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.completeLogin(user, client)
+}
+
+// LoginWithPasskey finishes a passwordless login. A user-verified passkey already
+// combines possession and a local PIN/biometric, so no TOTP challenge follows.
+func (s *AuthService) LoginWithPasskey(sessionID uuid.UUID, response []byte, client ClientInfo) (*LoginResult, error) {
+	user, err := s.passkeys.FinishLogin(sessionID, response)
 	if err != nil {
-		return "", "", nil, err
+		return nil, err
 	}
 
-	// Save refresh token
-	rt := models.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+	// A lockout applies to every way of signing in
+	if err := s.throttle.CheckUser(user); err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is disabled")
+	}
+
+	if config.RequireEmailVerification() && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.startSession(user, client)
+}
+
+// completeLogin starts a session, or hands out an MFA challenge when a second factor is required
+func (s *AuthService) completeLogin(user *models.User, client ClientInfo) (*LoginResult, error) {
+	if user.TOTPEnabled {
+		mfaToken, err := utils.GenerateMFAToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.startSession(user, client)
+}
+
+// startSession issues an access/refresh token pair for an authenticated user on a new device session
+// Only a completed login (every required factor) resets the throttle's failure count
+func (s *AuthService) startSession(user *models.User, client ClientInfo) (*LoginResult, error) {
+	s.throttle.RecordSuccess(user.Email, client)
+
+	rt, err := s.createRefreshToken(config.DB, &models.RefreshToken{
+		UserID:     user.ID,
+		FamilyID:   uuid.New(),
+		DeviceName: utils.DeviceName(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.generateAccessToken(user, rt.FamilyID)
+	if err != nil {
+		return nil, err
 	}
-	config.DB.Create(&rt)
 
 	// Update last login
 	now := time.Now()
 	user.LastLoginAt = &now
-	config.DB.Save(&user)
+	config.DB.Model(user).Update("last_login_at", now)
 
-	return accessToken, refreshToken, &user, nil
+	return &LoginResult{
+		AccessToken:  accessToken,
+		RefreshToken: rt.Token,
+		User:         user,
+	}, nil
 }
 
-// RefreshAccessToken generates new access token from refresh token
-func (s *AuthService) RefreshAccessToken(refreshToken string) (string, error) {
+// RefreshAccessToken rotates a refresh token and returns a new access/refresh token pair
+func (s *AuthService) RefreshAccessToken(refreshToken string, client ClientInfo) (string, string, error) {
 	// Verify refresh token
 	userID, err := utils.VerifyRefreshToken(refreshToken)
 	if err != nil {
-		return "", errors.New("invalid refresh token")
+		return "", "", errors.New("invalid refresh token")
 	}
 
 	// Check if refresh token exists in database
 	var rt models.RefreshToken
 	if err := config.DB.Where("token = ? AND user_id = ?", refreshToken, userID).First(&rt).Error; err != nil {
-		return "", errors.New("refresh token not found")
+		return "", "", errors.New("refresh token not found")
+	}
+
+	// A token that was already rotated is being replayed: assume it was stolen
+	if rt.IsRotated() {
+		s.revokeFamily(&rt, client)
+		return "", "", errRefreshTokenReuse
+	}
+
+	// Check if revoked
+	if rt.IsRevoked() {
+		return "", "", errors.New("refresh token revoked")
 	}
 
 	// Check if expired
 	if rt.IsExpired() {
-		return "", errors.New("refresh token expired")
+		return "", "", errors.New("refresh token expired")
 	}
 
 	// Get user
 	var user models.User
-	if err := config.DB.Preload("Role").First(&user, userID).Error; err != nil {
-		return "", errors.New("user not found")
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return "", "", errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return "", "", errors.New("account is disabled")
 	}
 
 	// Generate new access token
-	role := ""
-	if user.Role != nil {
-		role = user.Role.Name
+	accessToken, err := s.generateAccessToken(&user, rt.Family())
+	if err != nil {
+		return "", "", err
+	}
+
+	// Rotate refresh token within the same family, keeping the session's device details
+	userAgent := client.UserAgent
+	if userAgent == "" {
+		userAgent = rt.UserAgent
+	}
+	now := time.Now()
+
+	var next *models.RefreshToken
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		next, err = s.createRefreshToken(tx, &models.RefreshToken{
+			UserID:     user.ID,
+			FamilyID:   rt.Family(),
+			DeviceName: rt.DeviceName,
+			UserAgent:  userAgent,
+			IPAddress:  client.IPAddress,
+			LastUsedAt: &now,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Only one concurrent request may rotate the same token
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", rt.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReuse
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReuse) {
+			s.revokeFamily(&rt, client)
+		}
+		return "", "", err
+	}
+
+	return accessToken, next.Token, nil
+}
+
+// Logout revokes a single refresh token
+func (s *AuthService) Logout(refreshToken string) error {
+	// Verify refresh token
+	userID, err := utils.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	// Revoke token (already revoked tokens are left untouched)
+	if err := config.DB.Model(&models.RefreshToken{}).
+		Where("token = ? AND user_id = ? AND revoked_at IS NULL", refreshToken, userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	authz.InvalidateUser(userID)
+	return nil
+}
+
+// LogoutAll revokes every active refresh token of a user
+func (s *AuthService) LogoutAll(userID uuid.UUID) error {
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.revokeAllRefreshTokens(tx, userID); err != nil {
+			return err
+		}
+		return bumpTokenVersion(tx, userID)
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(userID)
+	return nil
+}
+
+// ForgotPassword emails a password reset link. It succeeds silently for unknown
+// emails so the response does not reveal which accounts exist.
+func (s *AuthService) ForgotPassword(email string) error {
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !user.IsActive {
+		return nil
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return s.issuePasswordReset(tx, &user, EmailTemplatePasswordReset, &EmailData{})
+	})
+}
+
+// ResetPassword sets a new password using a password reset token
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	var reset models.PasswordReset
+	if err := config.DB.Where("token = ?", utils.HashToken(token)).First(&reset).Error; err != nil {
+		return errInvalidResetToken
+	}
+
+	if !reset.IsValid() {
+		return errInvalidResetToken
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", reset.UserID).Error; err != nil {
+		return errInvalidResetToken
+	}
+
+	if err := s.passwordPolicy.Validate(newPassword, &user); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Mark token as used (guards against concurrent use of the same token)
+		result := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used = ?", reset.ID, false).
+			Update("used", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidResetToken
+		}
+
+		// The link was emailed to the account's address, so redeeming it (including an
+		// invite) proves the address
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", reset.UserID).
+			Updates(map[string]interface{}{
+				"password_hash":  hashedPassword,
+				"email_verified": true,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := s.passwordPolicy.RecordPassword(tx, reset.UserID, hashedPassword); err != nil {
+			return err
+		}
+
+		// Sign out every session that may have used the old password
+		if err := s.revokeAllRefreshTokens(tx, reset.UserID); err != nil {
+			return err
+		}
+		return bumpTokenVersion(tx, reset.UserID)
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(reset.UserID)
+	return nil
+}
+
+// createRefreshToken signs a refresh token for the given session record and stores it
+func (s *AuthService) createRefreshToken(tx *gorm.DB, rt *models.RefreshToken) (*models.RefreshToken, error) {
+	refreshToken, err := utils.GenerateRefreshToken(rt.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.Token = refreshToken
+	rt.ExpiresAt = time.Now().Add(utils.RefreshTokenTTL())
+	if err := tx.Create(rt).Error; err != nil {
+		return nil, err
+	}
+
+	return rt, nil
+}
+
+// generateAccessToken signs an access token bound to a session (refresh token family)
+func (s *AuthService) generateAccessToken(user *models.User, sessionID uuid.UUID) (string, error) {
+	return utils.GenerateAccessToken(utils.Claims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.RoleName(),
+		SessionID: sessionID,
+		Version:   user.TokenVersion,
+	})
+}
+
+// VerifyEmail marks the user's email as verified using a verification token
+func (s *AuthService) VerifyEmail(token string) error {
+	var verification models.EmailVerification
+	if err := config.DB.Where("token = ?", utils.HashToken(token)).First(&verification).Error; err != nil {
+		return errInvalidVerificationToken
+	}
+
+	if !verification.IsValid() {
+		return errInvalidVerificationToken
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EmailVerification{}).
+			Where("id = ? AND used = ?", verification.ID, false).
+			Update("used", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidVerificationToken
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", verification.UserID).
+			Update("email_verified", true).Error
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(verification.UserID)
+	return nil
+}
+
+// ResendVerification emails a new verification link. Unknown or already verified
+// emails and requests within the resend interval are ignored silently so the
+// response does not reveal which accounts exist.
+func (s *AuthService) ResendVerification(email string) error {
+	var user models.User
+	if err := config.DB.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerified || !user.IsActive {
+		return nil
+	}
+
+	// Throttle: at most one verification email per interval
+	interval := config.GetEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+	var recent int64
+	if err := config.DB.Model(&models.EmailVerification{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-interval)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return s.issueEmailVerification(tx, &user)
+	})
+}
+
+// UpdateProfile updates the user's own profile fields; nil fields are left unchanged
+func (s *AuthService) UpdateProfile(userID uuid.UUID, name, locale *string) (*models.User, error) {
+	updates := map[string]interface{}{}
+
+	if name != nil {
+		trimmed := strings.TrimSpace(*name)
+		if trimmed == "" {
+			return nil, errors.New("name is required")
+		}
+		updates["name"] = trimmed
+	}
+
+	if locale != nil {
+		trimmed := strings.TrimSpace(*locale)
+		if trimmed == "" || len(trimmed) > 10 {
+			return nil, errors.New("invalid locale")
+		}
+		updates["locale"] = trimmed
+	}
+
+	if len(updates) > 0 {
+		if err := config.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+		authz.InvalidateUser(userID)
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return &user, nil
+}
+
+// ChangePassword replaces the password after checking the current one. Every session is
+// signed out and a fresh one is returned for the device making the change.
+func (s *AuthService) ChangePassword(userID uuid.UUID, currentPassword, newPassword string, client ClientInfo) (*LoginResult, error) {
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	// Guessing the current password with a stolen access token is throttled like a login
+	attempt, err := s.throttle.Begin(user.Email, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(currentPassword, user.PasswordHash) {
+		attempt.Fail(&user)
+		return nil, errWrongPassword
+	}
+	attempt.Release()
+
+	if err := s.passwordPolicy.Validate(newPassword, &user); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			Update("password_hash", hashedPassword).Error; err != nil {
+			return err
+		}
+
+		if err := s.passwordPolicy.RecordPassword(tx, user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		if err := s.revokeAllRefreshTokens(tx, user.ID); err != nil {
+			return err
+		}
+		return bumpTokenVersion(tx, user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	// Reload for the new token version
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	return s.startSession(&user, client)
+}
+
+// RequestEmailChange emails a confirmation link to the new address. The email is only
+// changed once the link is opened, proving the user controls the new address.
+func (s *AuthService) RequestEmailChange(userID uuid.UUID, newEmail, password string, client ClientInfo) error {
+	newEmail = strings.TrimSpace(newEmail)
+	if _, err := mail.ParseAddress(newEmail); err != nil || strings.ContainsAny(newEmail, "<> ") {
+		return errors.New("invalid email address")
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	// Same throttling as ChangePassword, otherwise a stolen access token could guess
+	// the password here and then redirect the account's email
+	attempt, err := s.throttle.Begin(user.Email, client)
+	if err != nil {
+		return err
+	}
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(password, user.PasswordHash) {
+		attempt.Fail(&user)
+		return errWrongPassword
+	}
+	attempt.Release()
+
+	if strings.EqualFold(newEmail, user.Email) {
+		return errors.New("new email must differ from the current one")
+	}
+
+	var existing int64
+	config.DB.Model(&models.User{}).Where("LOWER(email) = LOWER(?)", newEmail).Count(&existing)
+	if existing > 0 {
+		return errEmailTaken
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the latest request can be confirmed
+		if err := tx.Model(&models.EmailChange{}).
+			Where("user_id = ? AND used = ?", user.ID, false).
+			Update("used", true).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.EmailChange{
+			UserID:    user.ID,
+			NewEmail:  newEmail,
+			Token:     utils.HashToken(token),
+			ExpiresAt: time.Now().Add(emailVerificationTTL()),
+		}).Error; err != nil {
+			return err
+		}
+
+		recipient := user
+		recipient.Email = newEmail
+		return s.queueEmail(tx, &recipient, EmailTemplateConfirmEmailChange, &EmailData{
+			Link:      fmt.Sprintf("%s/confirm-email-change?token=%s", config.FrontendURL(), url.QueryEscape(token)),
+			ExpiresIn: emailVerificationTTL(),
+			Email:     newEmail,
+		})
+	})
+}
+
+// ConfirmEmailChange swaps the user's email for the confirmed new address, notifies the old one
+// and sends a verification link to the new one
+func (s *AuthService) ConfirmEmailChange(token string) error {
+	var change models.EmailChange
+	if err := config.DB.Where("token = ?", utils.HashToken(token)).First(&change).Error; err != nil {
+		return errInvalidEmailChangeToken
+	}
+
+	if !change.IsValid() {
+		return errInvalidEmailChangeToken
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", change.UserID).Error; err != nil {
+		return errInvalidEmailChangeToken
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EmailChange{}).
+			Where("id = ? AND used = ?", change.ID, false).
+			Update("used", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidEmailChangeToken
+		}
+
+		// The address may have been registered since the request was made
+		var existing int64
+		if err := tx.Model(&models.User{}).
+			Where("LOWER(email) = LOWER(?) AND id <> ?", change.NewEmail, user.ID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errEmailTaken
+		}
+
+		// The new address goes through verification like a newly registered one
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"email":          change.NewEmail,
+			"email_verified": false,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Access tokens carry the old email
+		if err := bumpTokenVersion(tx, user.ID); err != nil {
+			return err
+		}
+
+		// Reset links were sent to the old address; redeeming one verifies the email
+		if err := tx.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND used = ?", user.ID, false).
+			Update("used", true).Error; err != nil {
+			return err
+		}
+
+		if err := s.queueEmail(tx, &user, EmailTemplateEmailChanged, &EmailData{
+			Email: change.NewEmail,
+		}); err != nil {
+			return err
+		}
+
+		// Replaces any pending verification link sent to the old address
+		changed := user
+		changed.Email = change.NewEmail
+		changed.EmailVerified = false
+		return s.issueEmailVerification(tx, &changed)
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(user.ID)
+	return nil
+}
+
+// issuePasswordReset invalidates pending reset tokens, creates a new one and queues
+// the email carrying the link (template is password_reset, or invite for new accounts)
+func (s *AuthService) issuePasswordReset(tx *gorm.DB, user *models.User, template string, data *EmailData) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Only the latest reset link stays usable
+	if err := tx.Model(&models.PasswordReset{}).
+		Where("user_id = ? AND used = ?", user.ID, false).
+		Update("used", true).Error; err != nil {
+		return err
+	}
+
+	// Invited users may not open their inbox right away
+	ttl := passwordResetTTL()
+	if template == EmailTemplateInvite {
+		ttl = inviteTTL()
+	}
+
+	if err := tx.Create(&models.PasswordReset{
+		UserID:    user.ID,
+		Token:     utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}).Error; err != nil {
+		return err
 	}
 
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, role)
+	data.Link = fmt.Sprintf("%s/reset-password?token=%s", config.FrontendURL(), url.QueryEscape(token))
+	data.ExpiresIn = ttl
+	return s.queueEmail(tx, user, template, data)
+}
+
+// issueEmailVerification invalidates pending verification tokens, creates a new one
+// and queues the verification email
+func (s *AuthService) issueEmailVerification(tx *gorm.DB, user *models.User) error {
+	token, err := utils.GenerateRandomToken(32)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	if err := tx.Model(&models.EmailVerification{}).
+		Where("user_id = ? AND used = ?", user.ID, false).
+		Update("used", true).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Create(&models.EmailVerification{
+		UserID:    user.ID,
+		Token:     utils.HashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationTTL()),
+	}).Error; err != nil {
+		return err
 	}
 
-	return accessToken, nil
+	return s.queueEmail(tx, user, EmailTemplateVerifyEmail, &EmailData{
+		Link:      fmt.Sprintf("%s/verify-email?token=%s", config.FrontendURL(), url.QueryEscape(token)),
+		ExpiresIn: emailVerificationTTL(),
+	})
+}
+
+// queueEmail renders a template in the user's locale and adds it to the outbox within tx
+func (s *AuthService) queueEmail(tx *gorm.DB, user *models.User, template string, data *EmailData) error {
+	data.AppName = config.AppName()
+	data.Name = user.Name
+
+	msg, err := RenderEmail(template, user.Locale, data)
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+
+	return s.emailOutbox.Enqueue(tx, template, msg)
+}
+
+// revokeAllRefreshTokens revokes every active refresh token of a user
+func (s *AuthService) revokeAllRefreshTokens(tx *gorm.DB, userID uuid.UUID) error {
+	if err := tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// rehashPassword replaces the user's password hash using the current hasher
+// Failures are only logged: the login itself already succeeded
+func (s *AuthService) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+
+	// Guard on the old hash so a concurrent password change is never overwritten
+	if err := config.DB.Model(&models.User{}).
+		Where("id = ? AND password_hash = ?", user.ID, user.PasswordHash).
+		Update("password_hash", hashedPassword).Error; err != nil {
+		log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hashedPassword
+}
+
+// bumpTokenVersion invalidates every access token issued to the user so far
+// Call it whenever a change must take effect before existing tokens expire
+// (password change, role change, deactivation, logout-all), and call
+// authz.InvalidateUser once the transaction has committed
+func bumpTokenVersion(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+// revokeFamily revokes every token descended from the same login and records the reuse
+func (s *AuthService) revokeFamily(rt *models.RefreshToken, client ClientInfo) {
+	familyID := rt.Family()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND (family_id = ? OR id = ?) AND revoked_at IS NULL", rt.UserID, familyID, familyID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return s.auditService.Log(tx, &models.AuditLog{
+			UserID:     &rt.UserID,
+			Action:     models.AuditActionRefreshTokenReuse,
+			EntityType: "refresh_token",
+			EntityID:   &rt.ID,
+			Changes: map[string]interface{}{
+				"family_id": familyID,
+			},
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to revoke refresh token family %s: %v", familyID, err)
+		return
+	}
+	// The user's snapshot remembers live sessions
+	authz.InvalidateUser(rt.UserID)
+}
+
+func passwordResetTTL() time.Duration {
+	return config.GetEnvDuration("PASSWORD_RESET_EXPIRY", time.Hour)
+}
+
+func inviteTTL() time.Duration {
+	return config.GetEnvDuration("INVITE_EXPIRY", 72*time.Hour)
+}
+
+func emailVerificationTTL() time.Duration {
+	return config.GetEnvDuration("EMAIL_VERIFICATION_EXPIRY", 24*time.Hour)
 }
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/go-next-template/internal/config"
+)
+
+// BreachedPasswordChecker looks passwords up in a local copy of a breached-password corpus
+//
+// The corpus uses the k-anonymity range format of Have I Been Pwned: one file per 5-character
+// SHA-1 prefix (e.g. "5BAA6" or "5BAA6.txt"), each line holding the remaining 35 hex characters
+// and a count ("1E4C9B93F3F0682250B6CF8331B7EE68FD8:3730471"). The password never leaves the server.
+type BreachedPasswordChecker struct {
+	Dir      string
+	MinCount int // Ignore hashes seen fewer times than this
+}
+
+// NewBreachedPasswordChecker returns a checker for BREACHED_PASSWORDS_DIR, or nil when it isn't set
+func NewBreachedPasswordChecker() *BreachedPasswordChecker {
+	dir := config.GetEnv("BREACHED_PASSWORDS_DIR", "")
+	if dir == "" {
+		return nil
+	}
+
+	return &BreachedPasswordChecker{
+		Dir:      dir,
+		MinCount: config.GetEnvInt("BREACHED_PASSWORDS_MIN_COUNT", 1),
+	}
+}
+
+// IsBreached reports whether the password appears in the corpus
+func (b *BreachedPasswordChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := b.openRange(prefix)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, countText, _ := strings.Cut(line, ":")
+		if !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+
+		count, err := strconv.Atoi(countText)
+		if err != nil {
+			count = 1
+		}
+		return count >= b.MinCount, nil
+	}
+
+	return false, scanner.Err()
+}
+
+func (b *BreachedPasswordChecker) openRange(prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(b.Dir, prefix))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(b.Dir, prefix+".txt"))
+	}
+	return file, err
+}
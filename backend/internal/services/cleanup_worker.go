@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+)
+
+// CleanupWorker periodically deletes expired short-lived rows (passkey ceremonies, external login states)
+// that were started but never finished
+type CleanupWorker struct {
+	interval time.Duration
+}
+
+func NewCleanupWorker() *CleanupWorker {
+	return &CleanupWorker{
+		interval: config.GetEnvDuration("CLEANUP_INTERVAL", time.Hour),
+	}
+}
+
+// Start purges expired rows in the background until ctx is cancelled
+func (w *CleanupWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.Purge(); err != nil {
+				log.Printf("Cleanup worker: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Purge deletes WebAuthn sessions and OAuth states past their expiry
+func (w *CleanupWorker) Purge() error {
+	now := time.Now()
+
+	if err := config.DB.Where("expires_at < ?", now).Delete(&models.WebAuthnSession{}).Error; err != nil {
+		return err
+	}
+
+	return config.DB.Where("expires_at < ?", now).Delete(&models.OAuthState{}).Error
+}
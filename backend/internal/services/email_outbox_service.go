@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+type EmailOutboxService struct{}
+
+func NewEmailOutboxService() *EmailOutboxService {
+	return &EmailOutboxService{}
+}
+
+// Enqueue stores a rendered email in the outbox. Pass the transaction of the change
+// that triggers the email so both are committed (or rolled back) together.
+func (s *EmailOutboxService) Enqueue(tx *gorm.DB, template string, msg *Message) error {
+	if tx == nil {
+		tx = config.DB
+	}
+
+	return tx.Create(&models.EmailOutbox{
+		To:            msg.To,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Template:      template,
+		Status:        models.EmailStatusPending,
+		MaxAttempts:   config.GetEnvInt("EMAIL_MAX_ATTEMPTS", 5),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// List returns outbox emails, optionally filtered by status, newest first
+func (s *EmailOutboxService) List(status string, limit, offset int) ([]models.EmailOutbox, int64, error) {
+	query := config.DB.Model(&models.EmailOutbox{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var emails []models.EmailOutbox
+	if err := query.Omit("text_body", "html_body").Order("created_at DESC").Limit(limit).Offset(offset).Find(&emails).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return emails, total, nil
+}
+
+// Get returns a single outbox email
+func (s *EmailOutboxService) Get(id uuid.UUID) (*models.EmailOutbox, error) {
+	var email models.EmailOutbox
+	if err := config.DB.First(&email, "id = ?", id).Error; err != nil {
+		return nil, errors.New("email not found")
+	}
+	return &email, nil
+}
+
+// Requeue resets a failed email so the worker delivers it again with a fresh attempt budget
+func (s *EmailOutboxService) Requeue(id uuid.UUID) (*models.EmailOutbox, error) {
+	email, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !email.IsFailed() {
+		return nil, errors.New("only failed emails can be re-queued")
+	}
+
+	if err := config.DB.Model(email).Updates(map[string]interface{}{
+		"status":          models.EmailStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return email, nil
+}
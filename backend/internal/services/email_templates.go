@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// Email template names
+const (
+	EmailTemplateVerifyEmail        = "verify_email"
+	EmailTemplatePasswordReset      = "password_reset"
+	EmailTemplateInvite             = "invite"
+	EmailTemplateConfirmEmailChange = "confirm_email_change"
+	EmailTemplateEmailChanged       = "email_changed"
+)
+
+// DefaultEmailLocale is used when a template is not available in the requested locale
+const DefaultEmailLocale = "en"
+
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// EmailData is the data passed to email templates
+type EmailData struct {
+	AppName     string
+	Name        string
+	Link        string
+	ExpiresIn   time.Duration
+	InviterName string
+	Email       string // New address for email change notifications
+}
+
+// emailTemplate holds the text (with a "subject" block) and HTML variants of one email
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var (
+	emailTemplates     map[string]*emailTemplate // keyed by "<locale>/<name>"
+	emailTemplatesErr  error
+	emailTemplatesOnce sync.Once
+)
+
+// RenderEmail renders the named email template in the given locale.
+// Locales fall back from "th-TH" to "th" and finally to DefaultEmailLocale.
+func RenderEmail(name, locale string, data *EmailData) (*Message, error) {
+	emailTemplatesOnce.Do(func() {
+		emailTemplates, emailTemplatesErr = loadEmailTemplates()
+	})
+	if emailTemplatesErr != nil {
+		return nil, emailTemplatesErr
+	}
+
+	tmpl := findEmailTemplate(name, locale)
+	if tmpl == nil {
+		return nil, fmt.Errorf("email template %q not found", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if tmpl.html != nil {
+		if err := tmpl.html.Execute(&html, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    html.String(),
+	}, nil
+}
+
+// EmailLocales returns the locales that have email templates
+func EmailLocales() []string {
+	locales := []string{}
+	entries, err := fs.ReadDir(emailTemplateFS, "templates/email")
+	if err != nil {
+		return locales
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			locales = append(locales, entry.Name())
+		}
+	}
+	return locales
+}
+
+func findEmailTemplate(name, locale string) *emailTemplate {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	candidates := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, DefaultEmailLocale)
+
+	for _, candidate := range candidates {
+		if tmpl, ok := emailTemplates[candidate+"/"+name]; ok {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+// loadEmailTemplates parses templates/email/<locale>/<name>.txt and the optional <name>.html
+func loadEmailTemplates() (map[string]*emailTemplate, error) {
+	templates := make(map[string]*emailTemplate)
+
+	root := "templates/email"
+	locales, err := fs.ReadDir(emailTemplateFS, root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dir := range locales {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := dir.Name()
+		funcs := map[string]interface{}{
+			"duration": func(d time.Duration) string { return formatDuration(locale, d) },
+		}
+
+		files, err := fs.ReadDir(emailTemplateFS, path.Join(root, locale))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if path.Ext(file.Name()) != ".txt" {
+				continue
+			}
+			name := strings.TrimSuffix(file.Name(), ".txt")
+			base := path.Join(root, locale, name)
+
+			text, err := texttemplate.New(name+".txt").Funcs(funcs).ParseFS(emailTemplateFS, base+".txt")
+			if err != nil {
+				return nil, err
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("email template %s.txt has no subject block", base)
+			}
+
+			tmpl := &emailTemplate{text: text}
+			if _, err := fs.Stat(emailTemplateFS, base+".html"); err == nil {
+				tmpl.html, err = htmltemplate.New(name+".html").Funcs(funcs).ParseFS(emailTemplateFS, base+".html")
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			templates[locale+"/"+name] = tmpl
+		}
+	}
+
+	return templates, nil
+}
+
+// formatDuration formats link lifetimes as whole hours or minutes in the given locale
+func formatDuration(locale string, d time.Duration) string {
+	unit, value := "minute", int(d.Minutes())
+	if d >= time.Hour && d%time.Hour == 0 {
+		unit, value = "hour", int(d.Hours())
+	}
+
+	switch locale {
+	case "th":
+		if unit == "hour" {
+			return fmt.Sprintf("%d ชั่วโมง", value)
+		}
+		return fmt.Sprintf("%d นาที", value)
+	default:
+		if value != 1 {
+			unit += "s"
+		}
+		return fmt.Sprintf("%d %s", value, unit)
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/textproto"
+	"time"
+
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailWorker delivers emails from the outbox, retrying failures with exponential backoff
+type EmailWorker struct {
+	mailer      Mailer
+	interval    time.Duration // How often the outbox is polled
+	batchSize   int
+	lease       time.Duration // How long a claimed email is hidden from other workers
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+}
+
+func NewEmailWorker(mailer Mailer) *EmailWorker {
+	return &EmailWorker{
+		mailer:      mailer,
+		interval:    config.GetEnvDuration("EMAIL_WORKER_INTERVAL", 10*time.Second),
+		batchSize:   config.GetEnvInt("EMAIL_WORKER_BATCH_SIZE", 20),
+		lease:       5 * time.Minute,
+		baseBackoff: config.GetEnvDuration("EMAIL_RETRY_BACKOFF", 30*time.Second),
+		maxBackoff:  time.Hour,
+	}
+}
+
+// Start polls the outbox in the background until ctx is cancelled
+func (w *EmailWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.ProcessBatch(); err != nil {
+				log.Printf("Email worker: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// ProcessBatch claims due emails and attempts to deliver them
+func (w *EmailWorker) ProcessBatch() error {
+	emails, err := w.claim()
+	if err != nil {
+		return err
+	}
+
+	for i := range emails {
+		w.deliver(&emails[i])
+	}
+
+	return nil
+}
+
+// claim locks due emails and pushes their next attempt past the lease, so concurrent
+// workers (or a crashed one) never deliver the same email twice within the lease
+func (w *EmailWorker) claim() ([]models.EmailOutbox, error) {
+	var emails []models.EmailOutbox
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(w.batchSize).
+			Find(&emails).Error; err != nil {
+			return err
+		}
+
+		if len(emails) == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, len(emails))
+		for i, email := range emails {
+			ids[i] = email.ID
+		}
+
+		return tx.Model(&models.EmailOutbox{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(w.lease)).Error
+	})
+
+	return emails, err
+}
+
+func (w *EmailWorker) deliver(email *models.EmailOutbox) {
+	err := w.mailer.Send(&Message{
+		To:      email.To,
+		Subject: email.Subject,
+		Text:    email.TextBody,
+		HTML:    email.HTMLBody,
+	})
+
+	attempts := email.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts": attempts,
+	}
+
+	switch {
+	case err == nil:
+		updates["status"] = models.EmailStatusSent
+		updates["sent_at"] = time.Now()
+		updates["last_error"] = ""
+		// The bodies hold one-time tokens; keep them only until delivery
+		updates["text_body"] = ""
+		updates["html_body"] = ""
+	case attempts >= email.MaxAttempts || isPermanentMailError(err):
+		updates["status"] = models.EmailStatusFailed
+		updates["last_error"] = err.Error()
+		log.Printf("Email %s to %s failed permanently: %v", email.ID, email.To, err)
+	default:
+		updates["next_attempt_at"] = time.Now().Add(w.backoff(attempts))
+		updates["last_error"] = err.Error()
+	}
+
+	if err := config.DB.Model(email).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update email %s: %v", email.ID, err)
+	}
+}
+
+// backoff returns baseBackoff * 2^(attempts-1), capped at maxBackoff
+func (w *EmailWorker) backoff(attempts int) time.Duration {
+	delay := w.baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+	return delay
+}
+
+// isPermanentMailError reports SMTP 5xx replies (e.g. unknown mailbox), which retrying won't fix
+func isPermanentMailError(err error) bool {
+	var smtpErr *textproto.Error
+	return errors.As(err, &smtpErr) && smtpErr.Code >= 500
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/your-org/go-next-template/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// ExternalIdentity is the identity asserted by an external provider after a successful login
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// IdentityProvider is implemented by each external login provider
+type IdentityProvider interface {
+	// Name is the provider key used in routes (/auth/oauth/:provider) and UserIdentity.Provider
+	Name() string
+	// AuthCodeURL returns the URL the browser is sent to, bound to state, nonce and the PKCE verifier
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	// Exchange redeems the authorization code and returns the verified identity
+	Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error)
+}
+
+var (
+	identityProviders   = map[string]IdentityProvider{}
+	identityProvidersMu sync.RWMutex
+	identityEnvOnce     sync.Once
+)
+
+// RegisterIdentityProvider adds (or replaces) an identity provider
+func RegisterIdentityProvider(provider IdentityProvider) {
+	identityProvidersMu.Lock()
+	defer identityProvidersMu.Unlock()
+	identityProviders[provider.Name()] = provider
+}
+
+// GetIdentityProvider returns a registered identity provider
+func GetIdentityProvider(name string) (IdentityProvider, bool) {
+	loadIdentityProvidersFromEnv()
+
+	identityProvidersMu.RLock()
+	defer identityProvidersMu.RUnlock()
+	provider, ok := identityProviders[name]
+	return provider, ok
+}
+
+// IdentityProviderNames returns the names of all registered identity providers
+func IdentityProviderNames() []string {
+	loadIdentityProvidersFromEnv()
+
+	identityProvidersMu.RLock()
+	defer identityProvidersMu.RUnlock()
+	names := make([]string, 0, len(identityProviders))
+	for name := range identityProviders {
+		names = append(names, name)
+	}
+	return names
+}
+
+// loadIdentityProvidersFromEnv registers providers listed in OIDC_PROVIDERS (e.g. "google,corp")
+// from OIDC_<NAME>_ISSUER/_CLIENT_ID/_CLIENT_SECRET/_REDIRECT_URL/_SCOPES, plus GitHub when
+// GITHUB_CLIENT_ID is set
+func loadIdentityProvidersFromEnv() {
+	identityEnvOnce.Do(func() {
+		for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+			name = strings.TrimSpace(strings.ToLower(name))
+			if name == "" {
+				continue
+			}
+			prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+			scopes := []string{oidc.ScopeOpenID, "email", "profile"}
+			if value := os.Getenv(prefix + "SCOPES"); value != "" {
+				scopes = strings.Split(value, ",")
+			}
+
+			RegisterIdentityProvider(NewOIDCProvider(
+				name,
+				os.Getenv(prefix+"ISSUER"),
+				os.Getenv(prefix+"CLIENT_ID"),
+				os.Getenv(prefix+"CLIENT_SECRET"),
+				config.GetEnv(prefix+"REDIRECT_URL", config.FrontendURL()+"/auth/callback/"+name),
+				scopes,
+			))
+		}
+
+		if clientID := os.Getenv("GITHUB_CLIENT_ID"); clientID != "" {
+			RegisterIdentityProvider(NewGitHubProvider(
+				clientID,
+				os.Getenv("GITHUB_CLIENT_SECRET"),
+				config.GetEnv("GITHUB_REDIRECT_URL", config.FrontendURL()+"/auth/callback/github"),
+			))
+		}
+	})
+}
+
+// OIDCProvider implements IdentityProvider for any OpenID Connect issuer (Google, Azure AD, Keycloak, ...).
+// Discovery runs on first use so an unreachable issuer doesn't block startup.
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCProvider {
+	return &OIDCProvider{
+		name:         name,
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// discover loads the issuer's endpoints and signing keys (/.well-known/openid-configuration)
+func (p *OIDCProvider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2 != nil {
+		return p.oauth2, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s discovery failed: %w", p.name, err)
+	}
+
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		RedirectURL:  p.redirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.clientID})
+
+	return p.oauth2, p.verifier, nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error) {
+	cfg, idTokenVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("no id_token in token response")
+	}
+
+	// Checks signature, issuer, audience and expiry
+	idToken, err := idTokenVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if idToken.Nonce != nonce {
+		return nil, errors.New("invalid id_token nonce")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	return &ExternalIdentity{
+		Provider:      p.name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// GitHubProvider implements IdentityProvider with GitHub OAuth2 (GitHub does not support OIDC logins)
+type GitHubProvider struct {
+	oauth2 *oauth2.Config
+	apiURL string
+}
+
+func NewGitHubProvider(clientID, clientSecret, redirectURL string) *GitHubProvider {
+	return &GitHubProvider{
+		oauth2: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		apiURL: "https://api.github.com",
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	return p.oauth2.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*ExternalIdentity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %w", err)
+	}
+	client := p.oauth2.Client(ctx, token)
+
+	var profile struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getJSON(client, "/user", &profile); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &ExternalIdentity{
+		Provider: p.Name(),
+		Subject:  fmt.Sprint(profile.ID),
+		Name:     profile.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = profile.Login
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (p *GitHubProvider) getJSON(client *http.Client, path string, v interface{}) error {
+	resp, err := client.Get(p.apiURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github api %s returned %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
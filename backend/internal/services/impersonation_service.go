@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	errImpersonationNotFound = errors.New("impersonation not found")
+	errImpersonationReason   = errors.New("a reason is required to impersonate a user")
+	errImpersonateSelf       = errors.New("you cannot impersonate yourself")
+	errImpersonateAdmin      = errors.New("admins cannot be impersonated")
+	errImpersonateInactive   = errors.New("disabled accounts cannot be impersonated")
+	errImpersonatorInvalid   = errors.New("impersonator is no longer an active admin")
+)
+
+// ImpersonationResult is returned when an admin starts impersonating a user
+type ImpersonationResult struct {
+	AccessToken   string                `json:"access_token"`
+	Impersonation *models.Impersonation `json:"impersonation"`
+}
+
+// ImpersonationTTL returns how long an impersonation lasts before its token stops working
+func ImpersonationTTL() time.Duration {
+	return config.GetEnvDuration("IMPERSONATION_TTL", 30*time.Minute)
+}
+
+// ImpersonationService lets support staff act as a customer. Starting, stopping and
+// every write made while impersonating are recorded in the audit log under the admin.
+type ImpersonationService struct {
+	auditService *AuditService
+}
+
+func NewImpersonationService() *ImpersonationService {
+	return &ImpersonationService{
+		auditService: NewAuditService(),
+	}
+}
+
+// List returns a page of impersonations, most recent first
+func (s *ImpersonationService) List(activeOnly bool, limit, offset int) ([]models.Impersonation, int64, error) {
+	query := config.DB.Model(&models.Impersonation{})
+	if activeOnly {
+		query = query.Where("ended_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var impersonations []models.Impersonation
+	if err := query.
+		Preload("Impersonator").
+		Preload("User").
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&impersonations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return impersonations, total, nil
+}
+
+// Start issues an impersonation token for the target user
+// Admins cannot be impersonated, so an impersonation token never grants admin access
+func (s *ImpersonationService) Start(targetID, actorID uuid.UUID, reason string, client ClientInfo) (*ImpersonationResult, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errImpersonationReason
+	}
+	if targetID == actorID {
+		return nil, errImpersonateSelf
+	}
+
+	var target models.User
+	if err := config.DB.Preload("Role").First(&target, "id = ?", targetID).Error; err != nil {
+		return nil, errUserNotFound
+	}
+	if !target.IsActive {
+		return nil, errImpersonateInactive
+	}
+
+	subject, err := authz.Load(config.DB, &target)
+	if err != nil {
+		return nil, err
+	}
+	if subject.IsAdmin() {
+		return nil, errImpersonateAdmin
+	}
+
+	impersonation := &models.Impersonation{
+		ImpersonatorID: actorID,
+		UserID:         target.ID,
+		Reason:         reason,
+		IPAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
+		ExpiresAt:      time.Now().Add(ImpersonationTTL()),
+	}
+
+	var accessToken string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(impersonation).Error; err != nil {
+			return err
+		}
+
+		token, err := utils.GenerateImpersonationToken(utils.Claims{
+			UserID:         target.ID,
+			Email:          target.Email,
+			Role:           target.RoleName(),
+			SessionID:      impersonation.ID,
+			Version:        target.TokenVersion,
+			ImpersonatorID: &actorID,
+		}, impersonation.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		accessToken = token
+
+		return s.audit(tx, actorID, models.AuditActionImpersonationStart, impersonation, map[string]interface{}{
+			"impersonation_id": impersonation.ID,
+			"reason":           reason,
+			"expires_at":       impersonation.ExpiresAt,
+		}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	impersonation.User = &target
+	return &ImpersonationResult{
+		AccessToken:   accessToken,
+		Impersonation: impersonation,
+	}, nil
+}
+
+// Stop ends an impersonation; its token is rejected from the next request on
+// actorID is the admin stopping it (the impersonator, or another admin)
+func (s *ImpersonationService) Stop(id, actorID uuid.UUID, client ClientInfo) error {
+	var impersonation models.Impersonation
+	if err := config.DB.First(&impersonation, "id = ?", id).Error; err != nil {
+		return errImpersonationNotFound
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Impersonation{}).
+			Where("id = ? AND ended_at IS NULL AND expires_at > ?", id, time.Now()).
+			Update("ended_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errImpersonationNotFound
+		}
+
+		changes := map[string]interface{}{
+			"impersonation_id": impersonation.ID,
+		}
+		if actorID != impersonation.ImpersonatorID {
+			changes["impersonator_id"] = impersonation.ImpersonatorID
+		}
+		return s.audit(tx, actorID, models.AuditActionImpersonationStop, &impersonation, changes, client)
+	}); err != nil {
+		return err
+	}
+
+	// The target's snapshot remembers the running impersonation
+	authz.InvalidateUser(impersonation.UserID)
+	return nil
+}
+
+// Active returns the impersonation of the target user behind a token, provided it is
+// still running and the impersonator is still an active admin. Running impersonations
+// are remembered on the target's snapshot, which Stop invalidates.
+func (s *ImpersonationService) Active(target *authz.Snapshot, id, impersonatorID uuid.UUID) (*models.Impersonation, error) {
+	cached, ok := target.Impersonation(id)
+	if !ok {
+		var impersonation models.Impersonation
+		if err := config.DB.
+			Where("id = ? AND user_id = ?", id, target.User.ID).
+			First(&impersonation).Error; err != nil {
+			return nil, errImpersonationNotFound
+		}
+		if impersonation.IsActive() {
+			target.RememberImpersonation(&impersonation)
+		}
+		cached = &impersonation
+	}
+	if cached.ImpersonatorID != impersonatorID || !cached.IsActive() {
+		return nil, errImpersonationNotFound
+	}
+
+	snapshot, err := authz.DefaultCache().Load(config.DB, impersonatorID)
+	if err != nil || !snapshot.User.IsActive || !snapshot.Subject.IsAdmin() {
+		return nil, errImpersonatorInvalid
+	}
+
+	// The remembered impersonation is shared between requests
+	impersonation := *cached
+	impersonator := snapshot.User
+	impersonation.Impersonator = &impersonator
+
+	return &impersonation, nil
+}
+
+// RecordWrite audits a write request made while impersonating
+// It runs before the request is handled, so a write is refused when it cannot be audited
+func (s *ImpersonationService) RecordWrite(impersonation *models.Impersonation, method, path string, client ClientInfo) error {
+	return s.audit(config.DB, impersonation.ImpersonatorID, models.AuditActionImpersonatedWrite, impersonation, map[string]interface{}{
+		"impersonation_id": impersonation.ID,
+		"method":           method,
+		"path":             path,
+	}, client)
+}
+
+// audit records an impersonation event under the acting admin, against the impersonated user
+func (s *ImpersonationService) audit(tx *gorm.DB, actorID uuid.UUID, action string, impersonation *models.Impersonation, changes map[string]interface{}, client ClientInfo) error {
+	return s.auditService.Log(tx, &models.AuditLog{
+		UserID:     &actorID,
+		Action:     action,
+		EntityType: "user",
+		EntityID:   &impersonation.UserID,
+		Changes:    changes,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	})
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTooManyAttempts is matched by every ThrottleError
+	ErrTooManyAttempts = errors.New("too many login attempts")
+)
+
+// ThrottleError rejects a login attempt until RetryAfter has passed
+type ThrottleError struct {
+	RetryAfter time.Duration
+	Locked     bool // The account itself is locked, not just slowed down
+}
+
+func (e *ThrottleError) Error() string {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if e.Locked {
+		return fmt.Sprintf("account is temporarily locked, try again in %d seconds", seconds)
+	}
+	return fmt.Sprintf("too many login attempts, try again in %d seconds", seconds)
+}
+
+// Is makes errors.Is(err, ErrTooManyAttempts) match
+func (e *ThrottleError) Is(target error) bool {
+	return target == ErrTooManyAttempts
+}
+
+// LoginThrottleService tracks failed logins per email and per client IP
+//
+// After LOGIN_DELAY_AFTER failures for an email, each further attempt must wait an exponentially
+// growing delay (LOGIN_DELAY_BASE, capped at LOGIN_DELAY_MAX). After LOGIN_LOCKOUT_THRESHOLD
+// failures the account is locked for LOGIN_LOCKOUT_DURATION. An IP with LOGIN_IP_MAX_FAILURES
+// failures is refused outright. Failures are counted within LOGIN_ATTEMPT_WINDOW.
+type LoginThrottleService struct {
+	auditService *AuditService
+}
+
+func NewLoginThrottleService() *LoginThrottleService {
+	return &LoginThrottleService{
+		auditService: NewAuditService(),
+	}
+}
+
+// Attempt is a credential check reserved by Begin. It is stored as a failure before the
+// credential is verified, so concurrent guesses are each counted and delayed; Release
+// drops it once the credential proves correct.
+type Attempt struct {
+	service *LoginThrottleService
+	id      uuid.UUID
+	email   string
+	client  ClientInfo
+}
+
+// Begin refuses an attempt that arrives before the current delay for the email or IP has
+// passed, and otherwise records it as a failure. The check and the insert run under
+// advisory locks on the IP and the email, so parallel requests see each other's attempts.
+func (s *LoginThrottleService) Begin(email string, client ClientInfo) (*Attempt, error) {
+	email = normalizeEmail(email)
+	attempt := &Attempt{service: s, email: email, client: client}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Always IP first, then email, so two attempts never wait on each other in a cycle
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "login-ip:"+client.IPAddress).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "login-email:"+email).Error; err != nil {
+			return err
+		}
+
+		if err := s.check(tx, email, client); err != nil {
+			return err
+		}
+
+		record := models.LoginAttempt{Email: email, IPAddress: client.IPAddress}
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		attempt.id = record.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// check refuses an attempt that arrives before the current delay for the email or IP has passed
+func (s *LoginThrottleService) check(tx *gorm.DB, email string, client ClientInfo) error {
+	now := time.Now()
+	since := now.Add(-attemptWindow())
+
+	if client.IPAddress != "" {
+		var ipFailures int64
+		if err := tx.Model(&models.LoginAttempt{}).
+			Where("ip_address = ? AND success = ? AND created_at > ?", client.IPAddress, false, since).
+			Count(&ipFailures).Error; err != nil {
+			return err
+		}
+
+		if ipFailures >= int64(config.GetEnvInt("LOGIN_IP_MAX_FAILURES", 50)) {
+			var oldest models.LoginAttempt
+			tx.Where("ip_address = ? AND success = ? AND created_at > ?", client.IPAddress, false, since).
+				Order("created_at").First(&oldest)
+			return &ThrottleError{RetryAfter: oldest.CreatedAt.Add(attemptWindow()).Sub(now)}
+		}
+	}
+
+	failures, last, err := s.recentFailures(tx, email, since)
+	if err != nil {
+		return err
+	}
+
+	if delay := loginDelay(failures); delay > 0 {
+		if wait := last.Add(delay).Sub(now); wait > 0 {
+			return &ThrottleError{RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// CheckUser refuses an attempt on a locked account
+func (s *LoginThrottleService) CheckUser(user *models.User) error {
+	if user.IsLocked() {
+		return &ThrottleError{RetryAfter: time.Until(*user.LockedUntil), Locked: true}
+	}
+	return nil
+}
+
+// Release drops the reserved failure because the credential was correct. It does not
+// reset earlier failures; only RecordSuccess after a completed login does.
+func (a *Attempt) Release() {
+	if err := config.DB.Delete(&models.LoginAttempt{}, "id = ?", a.id).Error; err != nil {
+		log.Printf("Failed to release login attempt: %v", err)
+	}
+}
+
+// Fail keeps the reserved failure and locks the account once the threshold is reached.
+// user is nil when the email doesn't belong to an account.
+func (a *Attempt) Fail(user *models.User) {
+	if user == nil || user.IsLocked() {
+		return
+	}
+
+	s := a.service
+	failures, _, err := s.recentFailures(config.DB, a.email, time.Now().Add(-attemptWindow()))
+	if err != nil || failures < int64(config.GetEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10)) {
+		return
+	}
+
+	lockedUntil := time.Now().Add(config.GetEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute))
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+			Update("locked_until", lockedUntil).Error; err != nil {
+			return err
+		}
+
+		return s.auditService.Log(tx, &models.AuditLog{
+			UserID:     &user.ID,
+			Action:     models.AuditActionAccountLocked,
+			EntityType: "user",
+			EntityID:   &user.ID,
+			Changes: map[string]interface{}{
+				"failed_attempts": failures,
+				"locked_until":    lockedUntil,
+			},
+			IPAddress: a.client.IPAddress,
+			UserAgent: a.client.UserAgent,
+		})
+	})
+	if err != nil {
+		log.Printf("Failed to lock account %s: %v", user.ID, err)
+		return
+	}
+
+	user.LockedUntil = &lockedUntil
+}
+
+// RecordSuccess records a successful attempt, which resets the email's failure count
+func (s *LoginThrottleService) RecordSuccess(email string, client ClientInfo) {
+	if err := config.DB.Create(&models.LoginAttempt{
+		Email:     normalizeEmail(email),
+		IPAddress: client.IPAddress,
+		Success:   true,
+	}).Error; err != nil {
+		log.Printf("Failed to record login attempt: %v", err)
+	}
+}
+
+// Unlock lifts a lockout and clears the account's failed attempts
+func (s *LoginThrottleService) Unlock(userID uuid.UUID, actorID uuid.UUID, client ClientInfo) error {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("locked_until", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("email = ? AND success = ?", normalizeEmail(user.Email), false).
+			Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		return s.auditService.Log(tx, &models.AuditLog{
+			UserID:     &actorID,
+			Action:     models.AuditActionAccountUnlocked,
+			EntityType: "user",
+			EntityID:   &user.ID,
+			IPAddress:  client.IPAddress,
+			UserAgent:  client.UserAgent,
+		})
+	})
+}
+
+// recentFailures counts failures for an email since the window start or its last success
+func (s *LoginThrottleService) recentFailures(db *gorm.DB, email string, since time.Time) (int64, time.Time, error) {
+	var lastSuccess models.LoginAttempt
+	err := db.Where("email = ? AND success = ? AND created_at > ?", email, true, since).
+		Order("created_at DESC").First(&lastSuccess).Error
+	if err == nil {
+		since = lastSuccess.CreatedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, time.Time{}, err
+	}
+
+	var result struct {
+		Count int64
+		Last  *time.Time
+	}
+	if err := db.Model(&models.LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS last").
+		Where("email = ? AND success = ? AND created_at > ?", email, false, since).
+		Scan(&result).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+
+	if result.Last == nil {
+		return result.Count, time.Time{}, nil
+	}
+	return result.Count, *result.Last, nil
+}
+
+// loginDelay returns the wait required after the given number of failures
+func loginDelay(failures int64) time.Duration {
+	free := int64(config.GetEnvInt("LOGIN_DELAY_AFTER", 3))
+	if failures < free {
+		return 0
+	}
+
+	maxDelay := config.GetEnvDuration("LOGIN_DELAY_MAX", 30*time.Second)
+	delay := config.GetEnvDuration("LOGIN_DELAY_BASE", time.Second)
+	for i := free; i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+func attemptWindow() time.Duration {
+	return config.GetEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/your-org/go-next-template/internal/config"
+)
+
+// Message represents an email message
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+var (
+	defaultMailer     Mailer
+	defaultMailerOnce sync.Once
+)
+
+// NewMailer returns the mailer configured by MAIL_DRIVER ("smtp" or "capture").
+// Without a driver, SMTP is used when SMTP_HOST is set, otherwise messages are captured.
+func NewMailer() Mailer {
+	defaultMailerOnce.Do(func() {
+		driver := config.GetEnv("MAIL_DRIVER", "")
+		if driver == "" {
+			driver = "capture"
+			if os.Getenv("SMTP_HOST") != "" {
+				driver = "smtp"
+			}
+		}
+
+		switch driver {
+		case "smtp":
+			defaultMailer = NewSMTPMailer()
+		default:
+			defaultMailer = NewCaptureMailer(os.Getenv("MAIL_CAPTURE_DIR"))
+		}
+	})
+	return defaultMailer
+}
+
+// SMTPMailer sends messages through an SMTP server
+type SMTPMailer struct {
+	Host      string
+	Port      string
+	Username  string
+	Password  string
+	FromEmail string
+	FromName  string
+}
+
+// NewSMTPMailer creates an SMTP mailer from SMTP_* environment variables
+func NewSMTPMailer() *SMTPMailer {
+	return &SMTPMailer{
+		Host:      os.Getenv("SMTP_HOST"),
+		Port:      config.GetEnv("SMTP_PORT", "587"),
+		Username:  os.Getenv("SMTP_USER"),
+		Password:  os.Getenv("SMTP_PASSWORD"),
+		FromEmail: os.Getenv("SMTP_FROM_EMAIL"),
+		FromName:  config.GetEnv("SMTP_FROM_NAME", config.AppName()),
+	}
+}
+
+// Send delivers the message. Port 465 uses implicit TLS, other ports upgrade with STARTTLS when offered.
+func (m *SMTPMailer) Send(msg *Message) error {
+	from := (&mail.Address{Name: m.FromName, Address: m.FromEmail}).String()
+	body, err := buildMIMEMessage(from, msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.Host, m.Port)
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	if m.Port != "465" {
+		return smtp.SendMail(addr, auth, m.FromEmail, []string{msg.To}, body)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.Host})
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.FromEmail); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// maxCapturedMessages bounds the in-memory history of CaptureMailer
+const maxCapturedMessages = 100
+
+// CaptureMailer keeps messages in memory (and optionally writes them as .eml files
+// to Dir) instead of sending them. Used in development and tests.
+type CaptureMailer struct {
+	Dir string
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewCaptureMailer creates a capture mailer; dir may be empty to keep messages in memory only
+func NewCaptureMailer(dir string) *CaptureMailer {
+	return &CaptureMailer{Dir: dir}
+}
+
+// Send captures the message
+func (m *CaptureMailer) Send(msg *Message) error {
+	m.mu.Lock()
+	m.messages = append(m.messages, *msg)
+	if len(m.messages) > maxCapturedMessages {
+		m.messages = m.messages[len(m.messages)-maxCapturedMessages:]
+	}
+	m.mu.Unlock()
+
+	if m.Dir == "" {
+		log.Printf("Captured email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+		return nil
+	}
+
+	body, err := buildMIMEMessage(config.GetEnv("SMTP_FROM_EMAIL", "noreply@localhost"), msg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000"), randomHex(4))
+	path := filepath.Join(m.Dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+
+	log.Printf("Captured email to %s: %s (%s)", msg.To, msg.Subject, path)
+	return nil
+}
+
+// Messages returns a copy of the captured messages
+func (m *CaptureMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]Message, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
+
+// Last returns the most recently captured message
+func (m *CaptureMailer) Last() (*Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.messages) == 0 {
+		return nil, false
+	}
+	msg := m.messages[len(m.messages)-1]
+	return &msg, true
+}
+
+// Reset clears the captured messages
+func (m *CaptureMailer) Reset() {
+	m.mu.Lock()
+	m.messages = nil
+	m.mu.Unlock()
+}
+
+// buildMIMEMessage renders a message as multipart/alternative (text + optional HTML)
+func buildMIMEMessage(from string, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	boundary := "boundary-" + randomHex(12)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if msg.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, s string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes issued at a time
+const recoveryCodeCount = 10
+
+var (
+	errInvalidMFACode     = errors.New("invalid two-factor code")
+	errInvalidMFAProof    = errors.New("invalid password or two-factor code")
+	errTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	errTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+)
+
+// TOTPEnrollment contains what an authenticator app needs to enroll a secret
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRCode     []byte `json:"-"` // PNG image of OTPAuthURL
+}
+
+type MFAService struct {
+	throttle *LoginThrottleService
+}
+
+func NewMFAService() *MFAService {
+	return &MFAService{
+		throttle: NewLoginThrottleService(),
+	}
+}
+
+// SetupTOTP generates a new pending TOTP secret; it becomes active after ConfirmTOTP
+func (s *MFAService) SetupTOTP(userID uuid.UUID) (*TOTPEnrollment, error) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.DB.Model(&user).Update("totp_secret", secret).Error; err != nil {
+		return nil, err
+	}
+
+	uri := utils.TOTPURI(config.AppName(), user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRCode:     png,
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves the app is set up,
+// and returns a fresh set of recovery codes
+func (s *MFAService) ConfirmTOTP(userID uuid.UUID, code string) ([]string, error) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	step, ok := utils.ValidateTOTPCode(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, errInvalidMFACode
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":   true,
+			"totp_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor authentication after re-checking password and a second factor
+func (s *MFAService) DisableTOTP(userID uuid.UUID, password, code string, client ClientInfo) error {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return errTOTPNotEnabled
+	}
+
+	// Guesses count towards the login throttle and lockout, and a wrong password is
+	// indistinguishable from a wrong code. The code is only tried with the right password,
+	// so a guess never consumes a recovery code.
+	attempt, err := s.throttle.Begin(user.Email, client)
+	if err != nil {
+		return err
+	}
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return err
+	}
+	if !utils.CheckPasswordHash(password, user.PasswordHash) || s.VerifyCode(&user, code) != nil {
+		attempt.Fail(&user)
+		return errInvalidMFAProof
+	}
+	attempt.Release()
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":   false,
+			"totp_secret":    "",
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(user.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes (requires a current TOTP code)
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string, client ClientInfo) ([]string, error) {
+	var user models.User
+	if err := config.DB.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errTOTPNotEnabled
+	}
+
+	// Code guesses count towards the login throttle and lockout
+	attempt, err := s.throttle.Begin(user.Email, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.throttle.CheckUser(&user); err != nil {
+		return nil, err
+	}
+	if err := s.verifyTOTP(&user, code); err != nil {
+		attempt.Fail(&user)
+		return nil, err
+	}
+	attempt.Release()
+
+	var codes []string
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// VerifyCode accepts either a TOTP code or an unused recovery code (consuming it)
+func (s *MFAService) VerifyCode(user *models.User, code string) error {
+	if !user.TOTPEnabled {
+		return errTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == utils.TOTPDigits {
+		return s.verifyTOTP(user, code)
+	}
+
+	return s.useRecoveryCode(user.ID, code)
+}
+
+// verifyTOTP validates a TOTP code and records its time step so it cannot be replayed
+func (s *MFAService) verifyTOTP(user *models.User, code string) error {
+	step, ok := utils.ValidateTOTPCode(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastStep {
+		return errInvalidMFACode
+	}
+
+	result := config.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvalidMFACode
+	}
+
+	user.TOTPLastStep = step
+	return nil
+}
+
+func (s *MFAService) useRecoveryCode(userID uuid.UUID, code string) error {
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes deletes existing recovery codes and stores hashes of new ones
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code like "abcd-efgh-ijkl-mnop" (80 random bits)
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	raw := strings.ToLower(base32.StdEncoding.EncodeToString(bytes))
+	return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16], nil
+}
+
+// normalizeRecoveryCode ignores case, spaces and dashes in user input
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/pkg/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+var (
+	errUnknownProvider    = errors.New("unknown identity provider")
+	errInvalidOAuthState  = errors.New("invalid or expired login state")
+	errAccountExists      = errors.New("an account with this email already exists")
+	errUnverifiedIdentity = errors.New("the identity provider has not verified this email address")
+	errUnverifiedAccount  = errors.New("an unverified account with this email already exists; sign in with your password and verify your email first")
+)
+
+type OAuthService struct{}
+
+func NewOAuthService() *OAuthService {
+	return &OAuthService{}
+}
+
+// AuthorizationURL starts an authorization code flow with PKCE and returns the provider login URL,
+// plus a binding value the caller stores in the browser (cookie) and passes back to Authenticate
+func (s *OAuthService) AuthorizationURL(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := GetIdentityProvider(providerName)
+	if !ok {
+		return "", "", errUnknownProvider
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := config.DB.Create(&models.OAuthState{
+		Provider:     provider.Name(),
+		StateHash:    utils.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(OAuthStateTTL),
+	}).Error; err != nil {
+		return "", "", err
+	}
+
+	return authURL, stateBinding(state), nil
+}
+
+// OAuthStateTTL is how long the user has to finish logging in at the provider
+const OAuthStateTTL = 10 * time.Minute
+
+// stateBinding ties a state to the browser that started the flow
+func stateBinding(state string) string {
+	return utils.HashToken(state)
+}
+
+// checkStateBinding refuses a callback whose state was not issued to this browser, so an attacker
+// cannot replay their own code and state in a victim's browser (login CSRF)
+func checkStateBinding(state, binding string) error {
+	if state == "" || binding == "" || subtle.ConstantTimeCompare([]byte(stateBinding(state)), []byte(binding)) != 1 {
+		return errInvalidOAuthState
+	}
+	return nil
+}
+
+// Authenticate completes the flow and returns the linked user, linking or creating one when needed
+// binding is the value returned by AuthorizationURL, read back from the browser
+func (s *OAuthService) Authenticate(ctx context.Context, providerName, code, state, binding string) (*models.User, error) {
+	provider, ok := GetIdentityProvider(providerName)
+	if !ok {
+		return nil, errUnknownProvider
+	}
+
+	if err := checkStateBinding(state, binding); err != nil {
+		return nil, err
+	}
+
+	// State is single-use and bound to the provider it was issued for
+	var flow models.OAuthState
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ? AND provider = ?", utils.HashToken(state), provider.Name()).
+			First(&flow).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&flow).Error
+	})
+	if err != nil || flow.IsExpired() {
+		return nil, errInvalidOAuthState
+	}
+
+	identity, err := provider.Exchange(ctx, code, flow.CodeVerifier, flow.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("identity provider returned no subject")
+	}
+
+	return s.resolveUser(identity)
+}
+
+// resolveUser finds the user linked to an external identity. Unknown identities are linked to an
+// existing account only when both the provider and the account verified the email; otherwise a new
+// account is created, provided the provider verified the email.
+func (s *OAuthService) resolveUser(identity *ExternalIdentity) (*models.User, error) {
+	var user models.User
+	now := time.Now()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var link models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&link).Error
+		if err == nil {
+			if err := tx.Preload("Role").First(&user, "id = ?", link.UserID).Error; err != nil {
+				return err
+			}
+			return tx.Model(&link).Updates(map[string]interface{}{
+				"email":         identity.Email,
+				"last_login_at": now,
+			}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" {
+			return errors.New("identity provider returned no email")
+		}
+
+		err = tx.Preload("Role").Where("LOWER(email) = LOWER(?)", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			if err := canLinkIdentity(&user, identity); err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := canCreateAccount(identity); err != nil {
+				return err
+			}
+
+			// Random password: the account can only sign in through the provider until a reset
+			password, err := utils.GenerateRandomToken(32)
+			if err != nil {
+				return err
+			}
+			hashedPassword, err := utils.HashPassword(password)
+			if err != nil {
+				return err
+			}
+
+			user = models.User{
+				Email:         normalizeEmail(identity.Email),
+				PasswordHash:  hashedPassword,
+				Name:          identity.Name,
+				EmailVerified: true,
+			}
+			if user.Name == "" {
+				user.Name = identity.Email
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Provider:    identity.Provider,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// canCreateAccount decides whether an external identity may create a new account. An unverified
+// provider email may belong to someone else, who would later take the account over through a
+// password reset while the attacker's identity link still signs in.
+func canCreateAccount(identity *ExternalIdentity) error {
+	if !identity.EmailVerified {
+		return errUnverifiedIdentity
+	}
+	return nil
+}
+
+// canLinkIdentity decides whether an external identity may sign in to an existing account with the same email.
+// An unverified local account may have been pre-registered by someone else with a password they know.
+func canLinkIdentity(user *models.User, identity *ExternalIdentity) error {
+	if !identity.EmailVerified {
+		return errAccountExists
+	}
+	if !user.EmailVerified {
+		return errUnverifiedAccount
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/your-org/go-next-template/internal/models"
+)
+
+const (
+	testClientID = "test-client"
+	testCode     = "test-code"
+)
+
+// mockOIDCServer is a minimal OpenID Connect issuer: discovery, JWKS and a PKCE-checking token endpoint
+type mockOIDCServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string                                   // code_challenge from the authorization request
+	claims    func(issuer, nonce string) jwt.MapClaims // id_token claims for the next exchange
+	nonce     string
+	signer    *rsa.PrivateKey // Key the id_token is signed with (defaults to key)
+}
+
+func newMockOIDCServer(t *testing.T) *mockOIDCServer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockOIDCServer{key: key}
+	m.claims = func(issuer, nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer,
+			"aud":            testClientID,
+			"sub":            "user-123",
+			"email":          "ada@example.com",
+			"email_verified": true,
+			"name":           "Ada",
+			"nonce":          nonce,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.URL,
+			"authorization_endpoint":                m.URL + "/authorize",
+			"token_endpoint":                        m.URL + "/token",
+			"jwks_uri":                              m.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", m.token)
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+// authorize records what the browser would send to the authorization endpoint
+func (m *mockOIDCServer) authorize(t *testing.T, authURL string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL lacks a PKCE challenge: %s", authURL)
+	}
+	if q.Get("client_id") != testClientID || q.Get("state") == "" || q.Get("nonce") == "" {
+		t.Fatalf("authorization URL lacks client_id, state or nonce: %s", authURL)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.challenge = q.Get("code_challenge")
+	m.nonce = q.Get("nonce")
+}
+
+func (m *mockOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != testCode {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	// PKCE: the verifier must hash to the challenge sent with the authorization request
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	signer := m.signer
+	if signer == nil {
+		signer = m.key
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims(m.URL, m.nonce))
+	token.Header["kid"] = "test"
+	idToken, err := token.SignedString(signer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// login runs the browser side of the flow: authorize, then exchange the code
+func login(t *testing.T, m *mockOIDCServer, provider *OIDCProvider, verifier, nonce string) (*ExternalIdentity, error) {
+	t.Helper()
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", nonce, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.authorize(t, authURL)
+
+	return provider.Exchange(context.Background(), testCode, verifier, nonce)
+}
+
+func newTestOIDCProvider(m *mockOIDCServer) *OIDCProvider {
+	return NewOIDCProvider("mock", m.URL, testClientID, "secret", "http://localhost:3000/auth/callback/mock", []string{"openid", "email"})
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	m := newMockOIDCServer(t)
+
+	identity, err := login(t, m, newTestOIDCProvider(m), "verifier-0123456789-0123456789-0123456789-01", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	want := ExternalIdentity{Provider: "mock", Subject: "user-123", Email: "ada@example.com", EmailVerified: true, Name: "Ada"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+}
+
+func TestOIDCProviderExchangeRejections(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(m *mockOIDCServer)
+		// wrongVerifier sends a PKCE verifier that does not match the challenge
+		wrongVerifier bool
+	}{
+		{
+			name: "nonce mismatch",
+			setup: func(m *mockOIDCServer) {
+				m.claims = withClaim(m.claims, "nonce", "replayed")
+			},
+		},
+		{
+			name: "wrong audience",
+			setup: func(m *mockOIDCServer) {
+				m.claims = withClaim(m.claims, "aud", "another-client")
+			},
+		},
+		{
+			name: "wrong issuer",
+			setup: func(m *mockOIDCServer) {
+				m.claims = withClaim(m.claims, "iss", "https://evil.example.com")
+			},
+		},
+		{
+			name: "expired",
+			setup: func(m *mockOIDCServer) {
+				m.claims = withClaim(m.claims, "exp", time.Now().Add(-time.Hour).Unix())
+			},
+		},
+		{
+			name: "unknown signing key",
+			setup: func(m *mockOIDCServer) {
+				m.signer = otherKey
+			},
+		},
+		{
+			name:          "PKCE verifier mismatch",
+			wrongVerifier: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDCServer(t)
+			if tt.setup != nil {
+				tt.setup(m)
+			}
+			provider := newTestOIDCProvider(m)
+
+			verifier := "verifier-0123456789-0123456789-0123456789-01"
+			authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce-1", verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.authorize(t, authURL)
+
+			if tt.wrongVerifier {
+				verifier = strings.Replace(verifier, "01", "10", 1)
+			}
+			if _, err := provider.Exchange(context.Background(), testCode, verifier, "nonce-1"); err == nil {
+				t.Fatal("expected the exchange to be rejected")
+			}
+		})
+	}
+}
+
+func withClaim(base func(issuer, nonce string) jwt.MapClaims, key string, value interface{}) func(issuer, nonce string) jwt.MapClaims {
+	return func(issuer, nonce string) jwt.MapClaims {
+		claims := base(issuer, nonce)
+		claims[key] = value
+		return claims
+	}
+}
+
+func TestCheckStateBinding(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string
+		binding string
+		wantErr bool
+	}{
+		{name: "issued to this browser", state: "abc", binding: stateBinding("abc")},
+		{name: "state from another flow", state: "attacker", binding: stateBinding("victim"), wantErr: true},
+		{name: "no cookie", state: "abc", binding: "", wantErr: true},
+		{name: "cookie holds the raw state", state: "abc", binding: "abc", wantErr: true},
+		{name: "no state", state: "", binding: stateBinding(""), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkStateBinding(tt.state, tt.binding); (err != nil) != tt.wantErr {
+				t.Errorf("checkStateBinding() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCanLinkIdentity(t *testing.T) {
+	tests := []struct {
+		name             string
+		accountVerified  bool
+		providerVerified bool
+		want             error
+	}{
+		{name: "both verified", accountVerified: true, providerVerified: true},
+		{name: "provider did not verify", accountVerified: true, providerVerified: false, want: errAccountExists},
+		{name: "pre-registered, unverified account", accountVerified: false, providerVerified: true, want: errUnverifiedAccount},
+		{name: "neither verified", accountVerified: false, providerVerified: false, want: errAccountExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{EmailVerified: tt.accountVerified}
+			identity := &ExternalIdentity{Email: "ada@example.com", EmailVerified: tt.providerVerified}
+			if got := canLinkIdentity(user, identity); got != tt.want {
+				t.Errorf("canLinkIdentity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanCreateAccount(t *testing.T) {
+	if err := canCreateAccount(&ExternalIdentity{Email: "ada@example.com", EmailVerified: true}); err != nil {
+		t.Errorf("verified identity refused: %v", err)
+	}
+	if err := canCreateAccount(&ExternalIdentity{Email: "ada@example.com"}); err != errUnverifiedIdentity {
+		t.Errorf("unverified identity: got %v, want %v", err, errUnverifiedIdentity)
+	}
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// WebAuthn ceremony purposes stored on models.WebAuthnSession
+const (
+	webAuthnPurposeRegistration = "registration"
+	webAuthnPurposeLogin        = "login"
+)
+
+var (
+	errPasskeyNotFound        = errors.New("passkey not found")
+	errInvalidWebAuthnSession = errors.New("invalid or expired passkey session")
+
+	webAuthnInstance *webauthn.WebAuthn
+	webAuthnErr      error
+	webAuthnOnce     sync.Once
+)
+
+type PasskeyService struct{}
+
+func NewPasskeyService() *PasskeyService {
+	return &PasskeyService{}
+}
+
+// webAuthn returns the relying party configuration built from WEBAUTHN_* environment variables
+func (s *PasskeyService) webAuthn() (*webauthn.WebAuthn, error) {
+	webAuthnOnce.Do(func() {
+		origins := strings.Split(config.GetEnv("WEBAUTHN_RP_ORIGINS", config.FrontendURL()), ",")
+		for i := range origins {
+			origins[i] = strings.TrimSpace(origins[i])
+		}
+
+		webAuthnInstance, webAuthnErr = webauthn.New(&webauthn.Config{
+			RPID:          config.GetEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName: config.AppName(),
+			RPOrigins:     origins,
+		})
+	})
+	return webAuthnInstance, webAuthnErr
+}
+
+// BeginRegistration starts registering a new passkey for the user
+func (s *PasskeyService) BeginRegistration(userID uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error) {
+	wa, err := s.webAuthn()
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	user, err := s.loadUser(userID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	// Don't register the same authenticator twice
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, cred := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, cred.Descriptor())
+	}
+
+	creation, session, err := wa.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	sessionID, err := s.saveSession(&userID, webAuthnPurposeRegistration, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return creation, sessionID, nil
+}
+
+// FinishRegistration verifies the authenticator response and stores the new passkey
+func (s *PasskeyService) FinishRegistration(userID, sessionID uuid.UUID, name string, response []byte) (*models.Credential, error) {
+	wa, err := s.webAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.takeSession(sessionID, &userID, webAuthnPurposeRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+
+	cred, err := wa.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, errors.New("passkey verification failed")
+	}
+
+	credential := newCredential(userID, name, cred)
+	if err := config.DB.Create(&credential).Error; err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+// newCredential converts a verified registration into the stored passkey
+func newCredential(userID uuid.UUID, name string, cred *webauthn.Credential) models.Credential {
+	if name == "" {
+		name = "Passkey"
+	}
+
+	transports := make(models.StringList, len(cred.Transport))
+	for i, t := range cred.Transport {
+		transports[i] = string(t)
+	}
+
+	return models.Credential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       cred.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  cred.Flags.BackupEligible,
+		BackupState:     cred.Flags.BackupState,
+	}
+}
+
+// BeginLogin starts a passwordless login; the browser lets the user pick a discoverable passkey
+func (s *PasskeyService) BeginLogin() (*protocol.CredentialAssertion, uuid.UUID, error) {
+	wa, err := s.webAuthn()
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	assertion, session, err := wa.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	sessionID, err := s.saveSession(nil, webAuthnPurposeLogin, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	return assertion, sessionID, nil
+}
+
+// FinishLogin verifies the assertion and returns the user owning the passkey
+func (s *PasskeyService) FinishLogin(sessionID uuid.UUID, response []byte) (*models.User, error) {
+	wa, err := s.webAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.takeSession(sessionID, nil, webAuthnPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+
+	owner, cred, err := validateLogin(wa, session, parsed, s.loadUser)
+	if err != nil {
+		return nil, errors.New("passkey verification failed")
+	}
+
+	// A signature counter that goes backwards indicates a cloned authenticator
+	if cred.Authenticator.CloneWarning {
+		config.DB.Model(&models.Credential{}).
+			Where("credential_id = ?", cred.ID).
+			Update("clone_warning", true)
+		return nil, errors.New("passkey verification failed")
+	}
+
+	if err := config.DB.Model(&models.Credential{}).
+		Where("credential_id = ?", cred.ID).
+		Updates(map[string]interface{}{
+			"sign_count":   cred.Authenticator.SignCount,
+			"backup_state": cred.Flags.BackupState,
+			"last_used_at": time.Now(),
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return owner.user, nil
+}
+
+// validateLogin verifies a discoverable login assertion, loading the passkey owner from its user handle
+func validateLogin(wa *webauthn.WebAuthn, session *webauthn.SessionData, parsed *protocol.ParsedCredentialAssertionData, load func(uuid.UUID) (*webAuthnUser, error)) (*webAuthnUser, *webauthn.Credential, error) {
+	var owner *webAuthnUser
+	cred, err := wa.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, err
+		}
+		owner, err = load(userID)
+		return owner, err
+	}, *session, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return owner, cred, nil
+}
+
+// List returns the user's passkeys
+func (s *PasskeyService) List(userID uuid.UUID) ([]models.Credential, error) {
+	var credentials []models.Credential
+	if err := config.DB.Where("user_id = ?", userID).Order("created_at").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// Delete removes one of the user's passkeys
+func (s *PasskeyService) Delete(userID, credentialID uuid.UUID) error {
+	result := config.DB.Where("id = ? AND user_id = ?", credentialID, userID).Delete(&models.Credential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errPasskeyNotFound
+	}
+	return nil
+}
+
+// saveSession persists ceremony state so the finish step can run on any instance
+func (s *PasskeyService) saveSession(userID *uuid.UUID, purpose string, session *webauthn.SessionData) (uuid.UUID, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	expiresAt := session.Expires
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(5 * time.Minute)
+	}
+
+	record := models.WebAuthnSession{
+		UserID:    userID,
+		Purpose:   purpose,
+		Data:      string(data),
+		ExpiresAt: expiresAt,
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return uuid.Nil, err
+	}
+
+	return record.ID, nil
+}
+
+// takeSession loads and deletes ceremony state, so every challenge is single-use
+func (s *PasskeyService) takeSession(id uuid.UUID, userID *uuid.UUID, purpose string) (*webauthn.SessionData, error) {
+	var record models.WebAuthnSession
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("id = ? AND purpose = ?", id, purpose)
+		if userID != nil {
+			query = query.Where("user_id = ?", *userID)
+		}
+		if err := query.First(&record).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+	if err != nil || record.IsExpired() {
+		return nil, errInvalidWebAuthnSession
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(record.Data), &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (s *PasskeyService) loadUser(userID uuid.UUID) (*webAuthnUser, error) {
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	var credentials []models.Credential
+	if err := config.DB.Where("user_id = ?", userID).Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+
+	return &webAuthnUser{user: &user, credentials: credentials}, nil
+}
+
+// webAuthnUser adapts models.User to the webauthn.User interface
+type webAuthnUser struct {
+	user        *models.User
+	credentials []models.Credential
+}
+
+// WebAuthnID returns the user handle (the raw user UUID)
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Name
+}
+
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.credentials))
+	for i, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, len(c.Transports))
+		for j, t := range c.Transports {
+			transports[j] = protocol.AuthenticatorTransport(t)
+		}
+
+		credentials[i] = webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       c.AAGUID,
+				SignCount:    c.SignCount,
+				CloneWarning: c.CloneWarning,
+			},
+		}
+	}
+	return credentials
+}
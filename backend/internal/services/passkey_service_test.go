@@ -0,0 +1,355 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/models"
+)
+
+const testOrigin = "http://localhost:3000"
+
+func TestMain(m *testing.M) {
+	os.Setenv("WEBAUTHN_RP_ID", "localhost")
+	os.Setenv("WEBAUTHN_RP_ORIGINS", testOrigin)
+	os.Exit(m.Run())
+}
+
+// softAuthenticator is an in-memory ES256 platform authenticator with "none" attestation
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatal(err)
+	}
+
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: testOrigin}
+}
+
+// authenticatorData builds rpIdHash | flags | signCount [| attested credential data]
+func (a *softAuthenticator) authenticatorData(t *testing.T, rpID string, attested bool) []byte {
+	t.Helper()
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	flags := byte(protocol.FlagUserPresent | protocol.FlagUserVerified)
+	if attested {
+		flags |= byte(protocol.FlagAttestedCredentialData)
+	}
+
+	var data bytes.Buffer
+	data.Write(rpIDHash[:])
+	data.WriteByte(flags)
+	binary.Write(&data, binary.BigEndian, a.signCount)
+
+	if attested {
+		publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+			PublicKeyData: webauthncose.PublicKeyData{
+				KeyType:   int64(webauthncose.EllipticKey),
+				Algorithm: int64(webauthncose.AlgES256),
+			},
+			Curve:  int64(webauthncose.P256),
+			XCoord: a.key.X.FillBytes(make([]byte, 32)),
+			YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data.Write(make([]byte, 16)) // AAGUID
+		binary.Write(&data, binary.BigEndian, uint16(len(a.credentialID)))
+		data.Write(a.credentialID)
+		data.Write(publicKey)
+	}
+
+	return data.Bytes()
+}
+
+func (a *softAuthenticator) clientData(ceremony protocol.CeremonyType, challenge []byte) []byte {
+	data, _ := json.Marshal(map[string]string{
+		"type":      string(ceremony),
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	return data
+}
+
+// register answers navigator.credentials.create() and returns the parsed browser response
+func (a *softAuthenticator) register(t *testing.T, creation *protocol.CredentialCreation) *protocol.ParsedCredentialCreationData {
+	t.Helper()
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(t, creation.Response.RelyingParty.ID, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData(protocol.CreateCeremony, creation.Response.Challenge)),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestation),
+		},
+	})
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse registration: %v", err)
+	}
+	return parsed
+}
+
+// assert answers navigator.credentials.get() for a discoverable login
+func (a *softAuthenticator) assert(t *testing.T, assertion *protocol.CredentialAssertion, userHandle []byte) *protocol.ParsedCredentialAssertionData {
+	t.Helper()
+
+	a.signCount++
+	authData := a.authenticatorData(t, assertion.Response.RelyingPartyID, false)
+	clientData := a.clientData(protocol.AssertCeremony, assertion.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+			"userHandle":        base64.RawURLEncoding.EncodeToString(userHandle),
+		},
+	})
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse assertion: %v", err)
+	}
+	return parsed
+}
+
+// roundTrip stores and reloads ceremony state the way saveSession/takeSession do
+func roundTrip(t *testing.T, session *webauthn.SessionData) *webauthn.SessionData {
+	t.Helper()
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored webauthn.SessionData
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	return &restored
+}
+
+// registerPasskey runs a registration ceremony and returns the user holding the new passkey
+func registerPasskey(t *testing.T, wa *webauthn.WebAuthn, authenticator *softAuthenticator) *webAuthnUser {
+	t.Helper()
+
+	user := &webAuthnUser{user: &models.User{ID: uuid.New(), Email: "ada@example.com", Name: "Ada"}}
+
+	creation, session, err := wa.BeginRegistration(user,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cred, err := wa.CreateCredential(user, *roundTrip(t, session), authenticator.register(t, creation))
+	if err != nil {
+		t.Fatalf("CreateCredential: %v", err)
+	}
+
+	credential := newCredential(user.user.ID, "", cred)
+	if credential.Name != "Passkey" {
+		t.Errorf("default name = %q, want Passkey", credential.Name)
+	}
+	if !bytes.Equal(credential.CredentialID, authenticator.credentialID) {
+		t.Errorf("stored credential ID does not match the authenticator")
+	}
+
+	user.credentials = append(user.credentials, credential)
+	return user
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	wa, err := NewPasskeyService().webAuthn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := newSoftAuthenticator(t)
+	user := registerPasskey(t, wa, authenticator)
+
+	assertion, session, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	load := func(id uuid.UUID) (*webAuthnUser, error) {
+		if id != user.user.ID {
+			return nil, errors.New("user not found")
+		}
+		return user, nil
+	}
+
+	owner, cred, err := validateLogin(wa, roundTrip(t, session), authenticator.assert(t, assertion, user.WebAuthnID()), load)
+	if err != nil {
+		t.Fatalf("validateLogin: %v", err)
+	}
+	if owner.user.ID != user.user.ID {
+		t.Errorf("owner = %s, want %s", owner.user.ID, user.user.ID)
+	}
+	if cred.Authenticator.SignCount != 1 || cred.Authenticator.CloneWarning {
+		t.Errorf("sign count = %d, clone warning = %v", cred.Authenticator.SignCount, cred.Authenticator.CloneWarning)
+	}
+}
+
+func TestPasskeyLoginRejections(t *testing.T) {
+	wa, err := NewPasskeyService().webAuthn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(a *softAuthenticator, user *webAuthnUser) []byte // returns the user handle to send
+	}{
+		{
+			name: "wrong origin",
+			tamper: func(a *softAuthenticator, user *webAuthnUser) []byte {
+				a.origin = "https://evil.example.com"
+				return user.WebAuthnID()
+			},
+		},
+		{
+			name: "different key",
+			tamper: func(a *softAuthenticator, user *webAuthnUser) []byte {
+				a.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				return user.WebAuthnID()
+			},
+		},
+		{
+			name: "unknown user handle",
+			tamper: func(a *softAuthenticator, user *webAuthnUser) []byte {
+				other := uuid.New()
+				return other[:]
+			},
+		},
+		{
+			name: "malformed user handle",
+			tamper: func(a *softAuthenticator, user *webAuthnUser) []byte {
+				return []byte("not-a-uuid")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := newSoftAuthenticator(t)
+			user := registerPasskey(t, wa, authenticator)
+			load := func(id uuid.UUID) (*webAuthnUser, error) {
+				if id != user.user.ID {
+					return nil, errors.New("user not found")
+				}
+				return user, nil
+			}
+
+			assertion, session, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			userHandle := tt.tamper(authenticator, user)
+			if _, _, err := validateLogin(wa, session, authenticator.assert(t, assertion, userHandle), load); err == nil {
+				t.Fatal("expected the login to be rejected")
+			}
+		})
+	}
+}
+
+func TestPasskeyAssertionBoundToChallenge(t *testing.T) {
+	wa, err := NewPasskeyService().webAuthn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := newSoftAuthenticator(t)
+	user := registerPasskey(t, wa, authenticator)
+	load := func(uuid.UUID) (*webAuthnUser, error) { return user, nil }
+
+	first, _, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An assertion signed for one challenge cannot complete another ceremony
+	if _, _, err := validateLogin(wa, second, authenticator.assert(t, first, user.WebAuthnID()), load); err == nil {
+		t.Fatal("expected an assertion for another challenge to be rejected")
+	}
+}
+
+func TestPasskeyCloneDetection(t *testing.T) {
+	wa, err := NewPasskeyService().webAuthn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := newSoftAuthenticator(t)
+	user := registerPasskey(t, wa, authenticator)
+	user.credentials[0].SignCount = 10 // The stored counter is ahead of the authenticator: a clone
+	load := func(uuid.UUID) (*webAuthnUser, error) { return user, nil }
+
+	assertion, session, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, cred, err := validateLogin(wa, session, authenticator.assert(t, assertion, user.WebAuthnID()), load)
+	if err != nil {
+		t.Fatalf("validateLogin: %v", err)
+	}
+	if !cred.Authenticator.CloneWarning {
+		t.Error("expected a clone warning when the signature counter goes backwards")
+	}
+}
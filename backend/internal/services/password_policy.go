@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Password policy violation codes
+const (
+	PasswordTooShort         = "too_short"
+	PasswordTooLong          = "too_long"
+	PasswordMissingUppercase = "missing_uppercase"
+	PasswordMissingLowercase = "missing_lowercase"
+	PasswordMissingDigit     = "missing_digit"
+	PasswordMissingSymbol    = "missing_symbol"
+	PasswordContainsPersonal = "contains_personal_info"
+	PasswordRecentlyUsed     = "recently_used"
+	PasswordBreached         = "breached"
+)
+
+// personalInfoMinFragmentLen ignores name or email fragments too short to matter ("Li", "jo")
+const personalInfoMinFragmentLen = 3
+
+// PasswordViolation is one reason a password was rejected
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every rule a password breaks
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// PasswordPolicy holds the rules new passwords must satisfy
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	HistorySize      int // Previous passwords that can't be reused (0 disables)
+	Breached         *BreachedPasswordChecker
+}
+
+// NewPasswordPolicy returns the policy configured by PASSWORD_* environment variables
+func NewPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:        config.GetEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:        config.GetEnvInt("PASSWORD_MAX_LENGTH", 128),
+		RequireUppercase: config.GetEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+		RequireLowercase: config.GetEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+		RequireDigit:     config.GetEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol:    config.GetEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		HistorySize:      config.GetEnvInt("PASSWORD_HISTORY_SIZE", 5),
+		Breached:         NewBreachedPasswordChecker(),
+	}
+}
+
+// Validate checks a new password for the user (whose ID is empty while registering)
+// It returns a *PasswordPolicyError listing every violation
+func (p *PasswordPolicy) Validate(password string, user *models.User) error {
+	var violations []PasswordViolation
+	add := func(code, message string) {
+		violations = append(violations, PasswordViolation{Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		add(PasswordTooShort, fmt.Sprintf("password must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add(PasswordTooLong, fmt.Sprintf("password must be at most %d characters", p.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		add(PasswordMissingUppercase, "password must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		add(PasswordMissingLowercase, "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add(PasswordMissingDigit, "password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add(PasswordMissingSymbol, "password must contain a symbol")
+	}
+
+	if user != nil && containsPersonalInfo(password, user) {
+		add(PasswordContainsPersonal, "password must not contain your email or name")
+	}
+
+	if user != nil && user.ID != uuid.Nil && p.recentlyUsed(password, user) {
+		add(PasswordRecentlyUsed, fmt.Sprintf("password must differ from your last %d passwords", p.HistorySize))
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			log.Printf("Breached password check failed: %v", err)
+		} else if breached {
+			add(PasswordBreached, "password appears in a known data breach, choose another one")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// recentlyUsed checks the password against the current hash and the last HistorySize hashes
+func (p *PasswordPolicy) recentlyUsed(password string, user *models.User) bool {
+	if p.HistorySize <= 0 {
+		return false
+	}
+
+	if user.PasswordHash != "" && utils.CheckPasswordHash(password, user.PasswordHash) {
+		return true
+	}
+
+	var history []models.PasswordHistory
+	if err := config.DB.Where("user_id = ?", user.ID).
+		Order("created_at DESC").
+		Limit(p.HistorySize).
+		Find(&history).Error; err != nil {
+		log.Printf("Failed to load password history for user %s: %v", user.ID, err)
+		return false
+	}
+
+	for _, entry := range history {
+		if utils.CheckPasswordHash(password, entry.PasswordHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordPassword stores a new password hash in the user's history and drops entries beyond HistorySize
+func (p *PasswordPolicy) RecordPassword(tx *gorm.DB, userID uuid.UUID, passwordHash string) error {
+	if p.HistorySize <= 0 {
+		return nil
+	}
+
+	if err := tx.Create(&models.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Where("user_id = ? AND id NOT IN (?)", userID,
+		tx.Model(&models.PasswordHistory{}).
+			Select("id").
+			Where("user_id = ?", userID).
+			Order("created_at DESC").
+			Limit(p.HistorySize),
+	).Delete(&models.PasswordHistory{}).Error
+}
+
+// containsPersonalInfo checks for the email's local part or any part of the name in the password
+func containsPersonalInfo(password string, user *models.User) bool {
+	lower := strings.ToLower(password)
+
+	fragments := strings.FieldsFunc(strings.ToLower(user.Name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if local, _, ok := strings.Cut(strings.ToLower(user.Email), "@"); ok {
+		fragments = append(fragments, local)
+	}
+
+	for _, fragment := range fragments {
+		if utf8.RuneCountInString(fragment) >= personalInfoMinFragmentLen && strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
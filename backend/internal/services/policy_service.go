@@ -0,0 +1,316 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/policy"
+	"gorm.io/gorm"
+)
+
+var (
+	errPolicyNotFound  = errors.New("policy not found")
+	errPolicyNameTaken = errors.New("policy name already exists")
+)
+
+// ErrResourceNotFound is returned by Evaluate when the record named by resourceID does not exist
+var ErrResourceNotFound = errors.New("resource not found")
+
+// resourceAttributes loads the attributes of a record for policy conditions (resource.<name>).
+// owner_id lets "own" role permissions apply.
+var resourceAttributes = map[string]func(id uuid.UUID) (map[string]interface{}, error){
+	"users": func(id uuid.UUID) (map[string]interface{}, error) {
+		var user models.User
+		if err := config.DB.Preload("Role").First(&user, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrResourceNotFound
+			}
+			return nil, err
+		}
+		subject, err := authz.Load(config.DB, &user)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"id":             user.ID.String(),
+			"owner_id":       user.ID.String(),
+			"email":          user.Email,
+			"role":           user.RoleName(),
+			"roles":          subject.RoleNames(),
+			"is_admin":       subject.IsAdmin(),
+			"is_active":      user.IsActive,
+			"email_verified": user.EmailVerified,
+		}, nil
+	},
+	"media": func(id uuid.UUID) (map[string]interface{}, error) {
+		var media models.Media
+		if err := config.DB.First(&media, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrResourceNotFound
+			}
+			return nil, err
+		}
+		attrs := map[string]interface{}{
+			"id":        media.ID.String(),
+			"category":  media.Category,
+			"mime_type": media.MimeType,
+			"size":      media.Size,
+		}
+		if media.UploadedByID != nil {
+			attrs["owner_id"] = media.UploadedByID.String()
+		}
+		return attrs, nil
+	},
+}
+
+// PolicyInput holds the policy fields an admin may set; nil fields are left unchanged on update
+type PolicyInput struct {
+	Name        *string
+	Description *string
+	Effect      *string
+	Resources   []string
+	Actions     []string
+	Conditions  models.PolicyConditions
+	IsActive    *bool
+}
+
+// ExplainInput describes a hypothetical request to evaluate without performing it
+type ExplainInput struct {
+	UserID     uuid.UUID
+	Resource   string
+	Action     string
+	ResourceID *uuid.UUID             // Load resource attributes from this record
+	Attributes map[string]interface{} // Extra or overriding resource attributes
+	Context    policy.Context
+	Draft      *models.Policy // Unsaved policy evaluated alongside the stored ones
+}
+
+// PolicyService manages attribute-based authorization policies and evaluates requests against them
+type PolicyService struct {
+	auditService *AuditService
+}
+
+func NewPolicyService() *PolicyService {
+	return &PolicyService{
+		auditService: NewAuditService(),
+	}
+}
+
+// List returns all policies ordered by name
+func (s *PolicyService) List() ([]models.Policy, error) {
+	var policies []models.Policy
+	if err := config.DB.Order("name ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Get returns a single policy
+func (s *PolicyService) Get(id uuid.UUID) (*models.Policy, error) {
+	var p models.Policy
+	if err := config.DB.First(&p, "id = ?", id).Error; err != nil {
+		return nil, errPolicyNotFound
+	}
+	return &p, nil
+}
+
+// Create validates and stores a new policy
+func (s *PolicyService) Create(input PolicyInput, actorID uuid.UUID, client ClientInfo) (*models.Policy, error) {
+	p := models.Policy{IsActive: true}
+	ApplyPolicyInput(&p, input)
+
+	if err := policy.Validate(&p); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(p.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&p).Error; err != nil {
+			return err
+		}
+		// IsActive has a database default, so an explicit false must be written separately
+		if !p.IsActive {
+			if err := tx.Model(&p).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+		return s.audit(tx, actorID, models.AuditActionPolicyCreated, p.ID, map[string]interface{}{"new": p}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Update validates and saves changes to a policy
+func (s *PolicyService) Update(id uuid.UUID, input PolicyInput, actorID uuid.UUID, client ClientInfo) (*models.Policy, error) {
+	old, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	p := *old
+	ApplyPolicyInput(&p, input)
+
+	if err := policy.Validate(&p); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(p.Name, p.ID); err != nil {
+		return nil, err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Policy{}).Where("id = ?", p.ID).Updates(map[string]interface{}{
+			"name":        p.Name,
+			"description": p.Description,
+			"effect":      p.Effect,
+			"resources":   p.Resources,
+			"actions":     p.Actions,
+			"conditions":  p.Conditions,
+			"is_active":   p.IsActive,
+		}).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, actorID, models.AuditActionPolicyUpdated, p.ID, map[string]interface{}{"old": old, "new": p}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(p.ID)
+}
+
+// Delete removes a policy
+func (s *PolicyService) Delete(id uuid.UUID, actorID uuid.UUID, client ClientInfo) error {
+	p, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Policy{}, "id = ?", p.ID).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, actorID, models.AuditActionPolicyDeleted, p.ID, map[string]interface{}{"old": p}, client)
+	})
+}
+
+// Evaluate decides whether the user may perform an action. When resourceID is set, the
+// record's attributes are loaded for resources that support it; attributes override them.
+func (s *PolicyService) Evaluate(user *models.User, subject *authz.Subject, resource, action string, resourceID *uuid.UUID, attributes map[string]interface{}, ctx policy.Context) (*policy.Decision, error) {
+	return s.evaluate(user, subject, resource, action, resourceID, attributes, ctx, nil)
+}
+
+// Explain evaluates a hypothetical request for any user, optionally with an unsaved draft
+// policy, and returns the full reasoning
+func (s *PolicyService) Explain(input ExplainInput) (*policy.Decision, error) {
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", input.UserID).Error; err != nil {
+		return nil, errUserNotFound
+	}
+
+	subject, err := authz.Load(config.DB, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Draft != nil {
+		if err := policy.Validate(input.Draft); err != nil {
+			return nil, err
+		}
+		input.Draft.IsActive = true
+	}
+
+	return s.evaluate(&user, subject, input.Resource, input.Action, input.ResourceID, input.Attributes, input.Context, input.Draft)
+}
+
+func (s *PolicyService) evaluate(user *models.User, subject *authz.Subject, resource, action string, resourceID *uuid.UUID, attributes map[string]interface{}, ctx policy.Context, draft *models.Policy) (*policy.Decision, error) {
+	attrs := map[string]interface{}{}
+	if resourceID != nil {
+		if load, ok := resourceAttributes[resource]; ok {
+			loaded, err := load(*resourceID)
+			if err != nil {
+				return nil, err
+			}
+			attrs = loaded
+		} else {
+			attrs["id"] = resourceID.String()
+		}
+	}
+	for name, value := range attributes {
+		attrs[name] = value
+	}
+
+	var policies []models.Policy
+	if err := config.DB.Where("is_active = ?", true).Order("name ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if draft != nil {
+		policies = append(policies, *draft)
+	}
+
+	decision := policy.Evaluate(policies, policy.Request{
+		User:       user,
+		Subject:    subject,
+		Resource:   resource,
+		Action:     action,
+		Attributes: attrs,
+		Context:    ctx,
+	})
+	return &decision, nil
+}
+
+// ApplyPolicyInput copies the set fields of input onto p
+func ApplyPolicyInput(p *models.Policy, input PolicyInput) {
+	if input.Name != nil {
+		p.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		p.Description = *input.Description
+	}
+	if input.Effect != nil {
+		p.Effect = *input.Effect
+	}
+	if input.Resources != nil {
+		p.Resources = models.StringList(input.Resources)
+	}
+	if input.Actions != nil {
+		p.Actions = models.StringList(input.Actions)
+	}
+	if input.Conditions != nil {
+		p.Conditions = input.Conditions
+	}
+	if input.IsActive != nil {
+		p.IsActive = *input.IsActive
+	}
+}
+
+func (s *PolicyService) ensureNameAvailable(name string, exceptID uuid.UUID) error {
+	var count int64
+	config.DB.Model(&models.Policy{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).
+		Count(&count)
+	if count > 0 {
+		return errPolicyNameTaken
+	}
+	return nil
+}
+
+func (s *PolicyService) audit(tx *gorm.DB, actorID uuid.UUID, action string, policyID uuid.UUID, changes map[string]interface{}, client ClientInfo) error {
+	return s.auditService.Log(tx, &models.AuditLog{
+		UserID:     &actorID,
+		Action:     action,
+		EntityType: "policy",
+		EntityID:   &policyID,
+		Changes:    changes,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	})
+}
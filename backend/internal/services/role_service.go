@@ -0,0 +1,430 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"gorm.io/gorm"
+)
+
+var (
+	errRoleNameMissing = errors.New("role name is required")
+	errRoleNameTaken   = errors.New("role name already exists")
+	errRoleInUse       = errors.New("role is still assigned to users")
+	errRoleHasChildren = errors.New("role is still the parent of other roles")
+	errParentNotFound  = errors.New("parent role not found")
+	errRoleHierarchy   = errors.New("parent role would create a cycle or exceed the maximum inheritance depth")
+)
+
+// RoleWithUsage is a role together with the number of users assigned to it
+type RoleWithUsage struct {
+	models.Role
+	UserCount int64 `json:"user_count"`
+}
+
+// RoleInput holds the role fields an admin may set; nil fields are left unchanged on update
+type RoleInput struct {
+	Name        *string
+	Description *string
+	Permissions map[string]interface{}
+	ParentID    *uuid.UUID // uuid.Nil removes the parent
+	IsActive    *bool
+}
+
+// RoleService implements admin role management. Every change is recorded in the audit log.
+type RoleService struct {
+	auditService *AuditService
+}
+
+func NewRoleService() *RoleService {
+	return &RoleService{
+		auditService: NewAuditService(),
+	}
+}
+
+// List returns all roles ordered by name with the number of users holding each
+func (s *RoleService) List() ([]RoleWithUsage, error) {
+	var roles []models.Role
+	if err := config.DB.Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		RoleID uuid.UUID
+		Count  int64
+	}
+	// A user counts once per role, whether it is their primary or an additional role
+	if err := config.DB.Raw(`SELECT role_id, COUNT(*) AS count FROM (
+			SELECT id AS user_id, role_id FROM users WHERE role_id IS NOT NULL
+			UNION
+			SELECT user_id, role_id FROM user_roles
+		) AS assignments GROUP BY role_id`).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	usage := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		usage[c.RoleID] = c.Count
+	}
+
+	result := make([]RoleWithUsage, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, RoleWithUsage{Role: role, UserCount: usage[role.ID]})
+	}
+	return result, nil
+}
+
+// Get returns a role with its user count
+func (s *RoleService) Get(id uuid.UUID) (*RoleWithUsage, error) {
+	var role models.Role
+	if err := config.DB.First(&role, "id = ?", id).Error; err != nil {
+		return nil, errRoleNotFound
+	}
+
+	count, err := s.userCount(config.DB, role.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoleWithUsage{Role: role, UserCount: count}, nil
+}
+
+// Create adds a new role after validating its permission document
+func (s *RoleService) Create(input RoleInput, actorID uuid.UUID, client ClientInfo) (*models.Role, error) {
+	if input.Name == nil || strings.TrimSpace(*input.Name) == "" {
+		return nil, errRoleNameMissing
+	}
+	name := strings.TrimSpace(*input.Name)
+
+	if err := s.ensureNameAvailable(config.DB, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	doc := input.Permissions
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	if err := permissions.Validate(doc); err != nil {
+		return nil, err
+	}
+
+	role := models.Role{
+		Name:        name,
+		Permissions: doc,
+		IsActive:    true,
+	}
+	if input.Description != nil {
+		role.Description = *input.Description
+	}
+	if input.IsActive != nil {
+		role.IsActive = *input.IsActive
+	}
+	if input.ParentID != nil && *input.ParentID != uuid.Nil {
+		role.ParentID = input.ParentID
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// The parent must not be deleted before this role commits
+		if role.ParentID != nil {
+			if err := lockRoles(tx); err != nil {
+				return err
+			}
+			if err := s.ensureParentExists(tx, *role.ParentID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		// IsActive has a database default, so an explicit false must be written separately
+		if !role.IsActive {
+			if err := tx.Model(&role).Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		return s.audit(tx, actorID, models.AuditActionRoleCreated, role.ID, map[string]interface{}{
+			"name":        role.Name,
+			"permissions": role.Permissions,
+			"parent_id":   role.ParentID,
+		}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// Update changes a role. Changes that would leave no active admin are refused.
+func (s *RoleService) Update(id uuid.UUID, input RoleInput, actorID uuid.UUID, client ClientInfo) (*models.Role, error) {
+	var role models.Role
+	if err := config.DB.First(&role, "id = ?", id).Error; err != nil {
+		return nil, errRoleNotFound
+	}
+
+	updates := map[string]interface{}{}
+	changes := map[string]interface{}{}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errRoleNameMissing
+		}
+		if name != role.Name {
+			if err := s.ensureNameAvailable(config.DB, name, role.ID); err != nil {
+				return nil, err
+			}
+			updates["name"] = name
+			changes["name"] = map[string]interface{}{"old": role.Name, "new": name}
+		}
+	}
+	if input.Description != nil && *input.Description != role.Description {
+		updates["description"] = *input.Description
+		changes["description"] = map[string]interface{}{"old": role.Description, "new": *input.Description}
+	}
+	if input.Permissions != nil {
+		if err := permissions.Validate(input.Permissions); err != nil {
+			return nil, err
+		}
+		updates["permissions"] = input.Permissions
+		changes["permissions"] = map[string]interface{}{"old": role.Permissions, "new": input.Permissions}
+	}
+	if input.ParentID != nil {
+		var parentID *uuid.UUID
+		if *input.ParentID != uuid.Nil {
+			parentID = input.ParentID
+		}
+		updates["parent_id"] = parentID
+		changes["parent_id"] = map[string]interface{}{"old": role.ParentID, "new": parentID}
+	}
+	if input.IsActive != nil && *input.IsActive != role.IsActive {
+		updates["is_active"] = *input.IsActive
+		changes["is_active"] = map[string]interface{}{"old": role.IsActive, "new": *input.IsActive}
+	}
+
+	if len(updates) == 0 {
+		return &role, nil
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		admins, err := lockAdmins(tx)
+		if err != nil {
+			return err
+		}
+
+		// Checked under the lock, so the parent cannot be deleted or re-parented meanwhile
+		if parentID, ok := updates["parent_id"].(*uuid.UUID); ok && parentID != nil {
+			if err := s.ensureParentExists(tx, *parentID); err != nil {
+				return err
+			}
+			cycle, err := authz.ParentCycle(tx, role.ID, *parentID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return errRoleHierarchy
+			}
+		}
+
+		if err := tx.Model(&role).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := ensureAdminRemains(tx, admins); err != nil {
+			return err
+		}
+		return s.audit(tx, actorID, models.AuditActionRoleUpdated, role.ID, changes, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Any cached user may inherit from this role
+	authz.InvalidateAll()
+
+	if err := config.DB.First(&role, "id = ?", role.ID).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Delete removes a role that no user holds and no other role inherits from
+func (s *RoleService) Delete(id uuid.UUID, actorID uuid.UUID, client ClientInfo) error {
+	var role models.Role
+	if err := config.DB.First(&role, "id = ?", id).Error; err != nil {
+		return errRoleNotFound
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Assignments and new child roles take the same lock, so none can slip in
+		// between these checks and the delete
+		if err := lockRoles(tx); err != nil {
+			return err
+		}
+
+		count, err := s.userCount(tx, role.ID)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errRoleInUse
+		}
+
+		var children int64
+		if err := tx.Model(&models.Role{}).Where("parent_id = ?", role.ID).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return errRoleHasChildren
+		}
+
+		if err := tx.Delete(&role).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, actorID, models.AuditActionRoleDeleted, role.ID, map[string]interface{}{
+			"name":        role.Name,
+			"permissions": role.Permissions,
+		}, client)
+	})
+	if err != nil {
+		return err
+	}
+
+	authz.InvalidateAll()
+	return nil
+}
+
+// adminUpgradeSetting marks that roles named "admin" were granted "*", so an admin
+// who later narrows that role is not overruled on the next restart
+const adminUpgradeSetting = "admin_role_upgraded"
+
+// UpgradeLegacyRoles rewrites role permission documents written before the rule
+// grammar, e.g. {"users": "crud"}, as explicit actions. Admin access used to follow
+// the role name "admin" rather than its permissions, so on the first run such roles
+// are also granted "*", keeping their deny rules. It runs at startup; upgraded roles
+// are recorded in the audit log without an actor.
+func (s *RoleService) UpgradeLegacyRoles() error {
+	upgraded := 0
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Replicas starting together upgrade one after the other; the later ones find
+		// the roles already upgraded and the marker in place
+		if err := lockRoles(tx); err != nil {
+			return err
+		}
+
+		var roles []models.Role
+		if err := tx.Find(&roles).Error; err != nil {
+			return err
+		}
+
+		var marker int64
+		if err := tx.Model(&models.Setting{}).Where("key = ?", adminUpgradeSetting).Count(&marker).Error; err != nil {
+			return err
+		}
+		upgradeAdmin := marker == 0
+
+		for _, role := range roles {
+			doc, changed := permissions.UpgradeLegacy(role.Permissions)
+			reason := "legacy permission upgrade"
+
+			if upgradeAdmin && role.Name == "admin" {
+				if all, granted := permissions.GrantAll(doc); granted {
+					doc = all
+					changed = true
+					reason = "legacy admin role upgrade"
+				}
+			}
+			if !changed {
+				continue
+			}
+
+			if err := tx.Model(&role).Update("permissions", doc).Error; err != nil {
+				return err
+			}
+			if err := s.auditService.Log(tx, &models.AuditLog{
+				Action:     models.AuditActionRoleUpdated,
+				EntityType: "role",
+				EntityID:   &role.ID,
+				Changes: map[string]interface{}{
+					"permissions": map[string]interface{}{"old": role.Permissions, "new": doc},
+					"reason":      reason,
+				},
+			}); err != nil {
+				return err
+			}
+			log.Printf("Upgraded permissions of role %q (%s)", role.Name, reason)
+			upgraded++
+		}
+
+		if !upgradeAdmin {
+			return nil
+		}
+		return tx.Create(&models.Setting{
+			Key:      adminUpgradeSetting,
+			Value:    "true",
+			Type:     "boolean",
+			Category: "system",
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if upgraded > 0 {
+		authz.InvalidateAll()
+	}
+	return nil
+}
+
+// userCount counts users holding the role as their primary or an additional role
+func (s *RoleService) userCount(db *gorm.DB, roleID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&models.User{}).
+		Where("role_id = ? OR id IN (?)", roleID,
+			db.Model(&models.UserRole{}).Select("user_id").Where("role_id = ?", roleID)).
+		Count(&count).Error
+	return count, err
+}
+
+func (s *RoleService) ensureParentExists(db *gorm.DB, parentID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Role{}).Where("id = ?", parentID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errParentNotFound
+	}
+	return nil
+}
+
+func (s *RoleService) ensureNameAvailable(db *gorm.DB, name string, exceptID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Role{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errRoleNameTaken
+	}
+	return nil
+}
+
+func (s *RoleService) audit(tx *gorm.DB, actorID uuid.UUID, action string, roleID uuid.UUID, changes map[string]interface{}, client ClientInfo) error {
+	return s.auditService.Log(tx, &models.AuditLog{
+		UserID:     &actorID,
+		Action:     action,
+		EntityType: "role",
+		EntityID:   &roleID,
+		Changes:    changes,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	})
+}
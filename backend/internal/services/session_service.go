@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"gorm.io/gorm"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// Session is a signed-in device: the active refresh token of a token family
+type Session struct {
+	ID         uuid.UUID  `json:"id"` // Refresh token family ID, also the "sid" claim of access tokens
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"` // When the user signed in on this device
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Current    bool       `json:"current"` // Session of the access token making the request
+}
+
+type SessionService struct {
+	auditService *AuditService
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		auditService: NewAuditService(),
+	}
+}
+
+// List returns the user's active sessions, most recently used first
+func (s *SessionService) List(userID, currentSessionID uuid.UUID) ([]Session, error) {
+	var tokens []models.RefreshToken
+	if err := config.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	// The active token was created at the last refresh; the session started with the family's first token
+	familyIDs := make([]uuid.UUID, 0, len(tokens))
+	for _, rt := range tokens {
+		familyIDs = append(familyIDs, rt.Family())
+	}
+
+	var starts []struct {
+		FamilyID  uuid.UUID
+		StartedAt time.Time
+	}
+	if len(familyIDs) > 0 {
+		if err := config.DB.Model(&models.RefreshToken{}).
+			Select("family_id, MIN(created_at) AS started_at").
+			Where("user_id = ? AND family_id IN ?", userID, familyIDs).
+			Group("family_id").
+			Scan(&starts).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	startedAt := make(map[uuid.UUID]time.Time, len(starts))
+	for _, start := range starts {
+		startedAt[start.FamilyID] = start.StartedAt
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, rt := range tokens {
+		session := Session{
+			ID:         rt.Family(),
+			DeviceName: rt.DeviceName,
+			UserAgent:  rt.UserAgent,
+			IPAddress:  rt.IPAddress,
+			CreatedAt:  rt.CreatedAt,
+			LastUsedAt: rt.LastUsedAt,
+			ExpiresAt:  rt.ExpiresAt,
+			Current:    rt.Family() == currentSessionID,
+		}
+		if start, ok := startedAt[session.ID]; ok {
+			session.CreatedAt = start
+		}
+		if session.LastUsedAt == nil {
+			session.LastUsedAt = &session.CreatedAt
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// Revoke signs one of the user's devices out
+// actorID is the user performing the action (the user themself, or an admin)
+func (s *SessionService) Revoke(userID, sessionID, actorID uuid.UUID, client ClientInfo) error {
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND (family_id = ? OR id = ?) AND revoked_at IS NULL", userID, sessionID, sessionID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSessionNotFound
+		}
+
+		return s.auditService.Log(tx, &models.AuditLog{
+			UserID:     &actorID,
+			Action:     models.AuditActionSessionRevoked,
+			EntityType: "session",
+			EntityID:   &sessionID,
+			Changes: map[string]interface{}{
+				"user_id": userID,
+			},
+			IPAddress: client.IPAddress,
+			UserAgent: client.UserAgent,
+		})
+	}); err != nil {
+		return err
+	}
+
+	// The user's snapshot remembers live sessions
+	authz.InvalidateUser(userID)
+	return nil
+}
+
+// IsActive checks if a session still has a usable refresh token. The auth middleware
+// remembers the answer on the user's snapshot, so every revocation must invalidate it.
+func (s *SessionService) IsActive(userID, sessionID uuid.UUID) bool {
+	var count int64
+	config.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND (family_id = ? OR id = ?) AND revoked_at IS NULL AND expires_at > ?", userID, sessionID, sessionID, time.Now()).
+		Count(&count)
+	return count > 0
+}
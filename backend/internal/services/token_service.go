@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/internal/permissions"
+	"github.com/your-org/go-next-template/pkg/utils"
+)
+
+// tokenLastUsedInterval limits how often last_used_at is written for busy tokens
+const tokenLastUsedInterval = time.Minute
+
+var (
+	errTokenNotFound    = errors.New("token not found")
+	errInvalidToken     = errors.New("invalid or expired token")
+	errTokenNameMissing = errors.New("token name is required")
+	errTokenNoScopes    = errors.New("at least one scope is required")
+	errTokenExpiry      = errors.New("expiry must be in the future")
+)
+
+type PersonalAccessTokenService struct{}
+
+func NewPersonalAccessTokenService() *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{}
+}
+
+// Create issues a new token for the user and returns it with its plaintext value (shown only once)
+func (s *PersonalAccessTokenService) Create(userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errTokenNameMissing
+	}
+	if len(scopes) == 0 {
+		return nil, "", errTokenNoScopes
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errTokenExpiry
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, "", errors.New("user not found")
+	}
+
+	// A token can never be granted more than its owner's roles allow
+	subject, err := authz.Load(config.DB, &user)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, scope := range scopes {
+		rule, err := permissions.ParseRule(scope)
+		if err != nil {
+			return nil, "", errors.New("invalid scope " + err.Error())
+		}
+		if problems := permissions.Check(permissions.Set{rule}); len(problems) > 0 {
+			return nil, "", errors.New("invalid scope " + scope + ": " + problems[0].Message)
+		}
+		if !subject.IsAdmin() && !subject.Permissions.Covers(rule) {
+			return nil, "", errors.New("scope not allowed by your role: " + scope)
+		}
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := models.PersonalAccessTokenPrefix + secret
+
+	token := models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: plaintext[:len(models.PersonalAccessTokenPrefix)+8],
+		TokenHash:   utils.HashToken(plaintext),
+		Scopes:      models.StringList(scopes),
+		ExpiresAt:   expiresAt,
+	}
+
+	if err := config.DB.Create(&token).Error; err != nil {
+		return nil, "", err
+	}
+
+	return &token, plaintext, nil
+}
+
+// List returns the user's tokens, newest first
+func (s *PersonalAccessTokenService) List(userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	if err := config.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Revoke revokes one of the user's tokens
+func (s *PersonalAccessTokenService) Revoke(userID, tokenID uuid.UUID) error {
+	result := config.DB.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate resolves a plaintext token to its record and owner
+func (s *PersonalAccessTokenService) Authenticate(plaintext string) (*models.PersonalAccessToken, *models.User, error) {
+	if !strings.HasPrefix(plaintext, models.PersonalAccessTokenPrefix) {
+		return nil, nil, errInvalidToken
+	}
+
+	var token models.PersonalAccessToken
+	if err := config.DB.Where("token_hash = ?", utils.HashToken(plaintext)).First(&token).Error; err != nil {
+		return nil, nil, errInvalidToken
+	}
+
+	if !token.IsValid() {
+		return nil, nil, errInvalidToken
+	}
+
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, "id = ?", token.UserID).Error; err != nil {
+		return nil, nil, errInvalidToken
+	}
+
+	// Throttle last-used writes so every API call doesn't hit the row
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenLastUsedInterval {
+		config.DB.Model(&token).Update("last_used_at", now)
+		token.LastUsedAt = &now
+	}
+
+	return &token, &user, nil
+}
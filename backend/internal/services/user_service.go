@@ -0,0 +1,554 @@
+package services
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/your-org/go-next-template/internal/authz"
+	"github.com/your-org/go-next-template/internal/config"
+	"github.com/your-org/go-next-template/internal/models"
+	"github.com/your-org/go-next-template/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	errUserNotFound   = errors.New("user not found")
+	errRoleNotFound   = errors.New("role not found")
+	errSelfManagement = errors.New("you cannot change your own role or deactivate your own account")
+	errLastAdmin      = errors.New("cannot remove the last active admin")
+)
+
+// userSortColumns maps the sort query values accepted by List to columns
+var userSortColumns = map[string]string{
+	"created_at":    "created_at",
+	"name":          "name",
+	"email":         "email",
+	"last_login_at": "last_login_at",
+}
+
+// UserFilter narrows down List results; nil or empty fields are ignored
+type UserFilter struct {
+	RoleID        *uuid.UUID
+	IsActive      *bool
+	EmailVerified *bool
+	Search        string // Matches name or email
+	Sort          string // created_at (default), name, email, last_login_at
+	Order         string // asc or desc (default)
+
+	Authorized func(*gorm.DB) *gorm.DB // Limits the list to users the caller may read, see middleware.AuthorizedScope
+}
+
+// CreateUserInput holds the fields an admin sets on a new user
+type CreateUserInput struct {
+	Email  string
+	Name   string
+	RoleID *uuid.UUID
+	Locale string
+}
+
+// UpdateUserInput holds the user fields an admin may change; nil fields are left unchanged
+type UpdateUserInput struct {
+	Email         *string
+	Name          *string
+	Locale        *string
+	EmailVerified *bool
+}
+
+// UserService implements admin user management. Every change is recorded in the audit log
+// with actorID as the acting admin.
+type UserService struct {
+	auditService *AuditService
+	authService  *AuthService
+}
+
+func NewUserService() *UserService {
+	return &UserService{
+		auditService: NewAuditService(),
+		authService:  NewAuthService(),
+	}
+}
+
+// List returns a page of users matching the filter
+func (s *UserService) List(filter UserFilter, limit, offset int) ([]models.User, int64, error) {
+	query := config.DB.Model(&models.User{})
+
+	if filter.Authorized != nil {
+		query = query.Scopes(filter.Authorized)
+	}
+	if filter.RoleID != nil {
+		query = query.Where("role_id = ? OR id IN (?)", *filter.RoleID,
+			config.DB.Model(&models.UserRole{}).Select("user_id").Where("role_id = ?", *filter.RoleID))
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.EmailVerified != nil {
+		query = query.Where("email_verified = ?", *filter.EmailVerified)
+	}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(search)) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := userSortColumns[filter.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if strings.EqualFold(filter.Order, "asc") {
+		direction = "ASC"
+	}
+
+	var users []models.User
+	if err := query.Preload("Role").Preload("Roles").
+		Order(column + " " + direction).
+		Order("id").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Get returns a single user with their roles
+func (s *UserService) Get(id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := config.DB.Preload("Role").Preload("Roles").First(&user, "id = ?", id).Error; err != nil {
+		return nil, errUserNotFound
+	}
+	return &user, nil
+}
+
+// Create adds a user with an unusable random password and emails them an invite to set their own
+func (s *UserService) Create(input CreateUserInput, actorID uuid.UUID, client ClientInfo) (*models.User, error) {
+	email := strings.TrimSpace(input.Email)
+	if _, err := mail.ParseAddress(email); err != nil || strings.ContainsAny(email, "<> ") {
+		return nil, errors.New("invalid email address")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if err := s.ensureEmailAvailable(email, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := input.Locale
+	if locale == "" {
+		locale = DefaultEmailLocale
+	}
+
+	var actor models.User
+	config.DB.First(&actor, "id = ?", actorID)
+
+	// Invited users are verified once they open the invite link (see AuthService.ResetPassword)
+	user := models.User{
+		Email:        email,
+		Name:         name,
+		PasswordHash: hashedPassword,
+		RoleID:       input.RoleID,
+		Locale:       locale,
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// The role must not be deleted before the user commits
+		if user.RoleID != nil {
+			if err := lockRoles(tx); err != nil {
+				return err
+			}
+			if err := s.ensureRoleExists(tx, *user.RoleID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		if err := s.authService.issuePasswordReset(tx, &user, EmailTemplateInvite, &EmailData{
+			InviterName: actor.Name,
+		}); err != nil {
+			return err
+		}
+
+		return s.audit(tx, actorID, models.AuditActionUserCreated, user.ID, map[string]interface{}{
+			"email":   user.Email,
+			"role_id": user.RoleID,
+		}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(user.ID)
+}
+
+// Update changes profile fields of a user
+func (s *UserService) Update(id uuid.UUID, input UpdateUserInput, actorID uuid.UUID, client ClientInfo) (*models.User, error) {
+	user, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		if _, err := mail.ParseAddress(email); err != nil || strings.ContainsAny(email, "<> ") {
+			return nil, errors.New("invalid email address")
+		}
+		if !strings.EqualFold(email, user.Email) {
+			if err := s.ensureEmailAvailable(email, user.ID); err != nil {
+				return nil, err
+			}
+			updates["email"] = email
+		}
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		updates["name"] = name
+	}
+	if input.Locale != nil {
+		locale := strings.TrimSpace(*input.Locale)
+		if locale == "" || len(locale) > 10 {
+			return nil, errors.New("invalid locale")
+		}
+		updates["locale"] = locale
+	}
+	if input.EmailVerified != nil {
+		updates["email_verified"] = *input.EmailVerified
+	}
+
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		// Access tokens carry the email
+		if _, ok := updates["email"]; ok {
+			if err := bumpTokenVersion(tx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		return s.audit(tx, actorID, models.AuditActionUserUpdated, user.ID, updates, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	return s.Get(user.ID)
+}
+
+// SetActive deactivates or reactivates a user. Deactivation signs the user out everywhere.
+func (s *UserService) SetActive(id uuid.UUID, active bool, actorID uuid.UUID, client ClientInfo) (*models.User, error) {
+	user, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive == active {
+		return user, nil
+	}
+
+	if !active && user.ID == actorID {
+		return nil, errSelfManagement
+	}
+
+	action := models.AuditActionUserReactivated
+	if !active {
+		action = models.AuditActionUserDeactivated
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var admins int64
+		if !active {
+			var err error
+			if admins, err = lockAdmins(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("is_active", active).Error; err != nil {
+			return err
+		}
+
+		if !active {
+			if err := s.authService.revokeAllRefreshTokens(tx, user.ID); err != nil {
+				return err
+			}
+			if err := bumpTokenVersion(tx, user.ID); err != nil {
+				return err
+			}
+			if err := ensureAdminRemains(tx, admins); err != nil {
+				return err
+			}
+		}
+
+		return s.audit(tx, actorID, action, user.ID, nil, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	return s.Get(user.ID)
+}
+
+// AssignRole changes a user's primary role (nil removes it). Existing access tokens stop working immediately.
+func (s *UserService) AssignRole(id uuid.UUID, roleID *uuid.UUID, actorID uuid.UUID, client ClientInfo) (*models.User, error) {
+	user, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == actorID {
+		return nil, errSelfManagement
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		admins, err := lockAdmins(tx)
+		if err != nil {
+			return err
+		}
+
+		if roleID != nil {
+			if err := s.ensureRoleExists(tx, *roleID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("role_id", roleID).Error; err != nil {
+			return err
+		}
+
+		if err := bumpTokenVersion(tx, user.ID); err != nil {
+			return err
+		}
+		if err := ensureAdminRemains(tx, admins); err != nil {
+			return err
+		}
+
+		return s.audit(tx, actorID, models.AuditActionUserRoleChanged, user.ID, map[string]interface{}{
+			"old_role_id": user.RoleID,
+			"new_role_id": roleID,
+		}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	return s.Get(user.ID)
+}
+
+// SetRoles replaces a user's additional roles. Existing access tokens stop working immediately.
+func (s *UserService) SetRoles(id uuid.UUID, roleIDs []uuid.UUID, actorID uuid.UUID, client ClientInfo) (*models.User, error) {
+	user, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == actorID {
+		return nil, errSelfManagement
+	}
+
+	seen := make(map[uuid.UUID]bool, len(roleIDs))
+	unique := make([]uuid.UUID, 0, len(roleIDs))
+	for _, roleID := range roleIDs {
+		if seen[roleID] {
+			continue
+		}
+		seen[roleID] = true
+		unique = append(unique, roleID)
+	}
+
+	oldRoleIDs := make([]uuid.UUID, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		oldRoleIDs = append(oldRoleIDs, role.ID)
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		admins, err := lockAdmins(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, roleID := range unique {
+			if err := s.ensureRoleExists(tx, roleID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		for _, roleID := range unique {
+			if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: roleID}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := bumpTokenVersion(tx, user.ID); err != nil {
+			return err
+		}
+		if err := ensureAdminRemains(tx, admins); err != nil {
+			return err
+		}
+
+		return s.audit(tx, actorID, models.AuditActionUserRoleChanged, user.ID, map[string]interface{}{
+			"old_role_ids": oldRoleIDs,
+			"new_role_ids": unique,
+		}, client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	authz.InvalidateUser(user.ID)
+
+	return s.Get(user.ID)
+}
+
+// ForcePasswordReset replaces the user's password with an unusable one, signs them out
+// everywhere and emails a password reset link
+func (s *UserService) ForcePasswordReset(id uuid.UUID, actorID uuid.UUID, client ClientInfo) error {
+	user, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password_hash", hashedPassword).Error; err != nil {
+			return err
+		}
+
+		if err := s.authService.revokeAllRefreshTokens(tx, user.ID); err != nil {
+			return err
+		}
+		if err := bumpTokenVersion(tx, user.ID); err != nil {
+			return err
+		}
+
+		if err := s.authService.issuePasswordReset(tx, user, EmailTemplatePasswordReset, &EmailData{}); err != nil {
+			return err
+		}
+
+		return s.audit(tx, actorID, models.AuditActionPasswordResetForced, user.ID, nil, client)
+	}); err != nil {
+		return err
+	}
+
+	authz.InvalidateUser(user.ID)
+	return nil
+}
+
+func (s *UserService) ensureEmailAvailable(email string, exceptID uuid.UUID) error {
+	var count int64
+	config.DB.Model(&models.User{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", email, exceptID).
+		Count(&count)
+	if count > 0 {
+		return errEmailTaken
+	}
+	return nil
+}
+
+func (s *UserService) ensureRoleExists(db *gorm.DB, roleID uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Role{}).Where("id = ?", roleID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errRoleNotFound
+	}
+	return nil
+}
+
+// roleLockKey identifies the transaction-scoped advisory lock that serializes role
+// assignments, role changes and role deletion, including every change able to remove an admin
+const roleLockKey int64 = 0x61646d696e73
+
+// lockRoles takes the role lock for the rest of tx
+func lockRoles(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", roleLockKey).Error
+}
+
+// lockAdmins takes the role lock for the rest of tx and counts the admins. Counting
+// before the lock would let two concurrent demotions each see another admin left.
+func lockAdmins(tx *gorm.DB) (int64, error) {
+	if err := lockRoles(tx); err != nil {
+		return 0, err
+	}
+	return authz.CountAdmins(tx)
+}
+
+// ensureAdminRemains refuses a change, made inside tx, that leaves no active admin
+// when there was at least one before. before must come from lockAdmins on the same tx.
+func ensureAdminRemains(tx *gorm.DB, before int64) error {
+	if before == 0 {
+		return nil
+	}
+
+	after, err := authz.CountAdmins(tx)
+	if err != nil {
+		return err
+	}
+	if after == 0 {
+		return errLastAdmin
+	}
+	return nil
+}
+
+func (s *UserService) audit(tx *gorm.DB, actorID uuid.UUID, action string, userID uuid.UUID, changes map[string]interface{}, client ClientInfo) error {
+	return s.auditService.Log(tx, &models.AuditLog{
+		UserID:     &actorID,
+		Action:     action,
+		EntityType: "user",
+		EntityID:   &userID,
+		Changes:    changes,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+	})
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
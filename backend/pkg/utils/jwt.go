@@ -9,16 +9,53 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token types, carried in the "typ" claim. Every token is signed with the key published in the
+// JWKS, so verifiers must check the type (and audience) to tell them apart.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa"
+)
+
 // Claims represents JWT claims
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sid"` // Refresh token family the access token was issued for (impersonation ID when impersonating)
+	Version   int       `json:"tv"`  // User.TokenVersion when the token was issued
+	Type      string    `json:"typ"` // Always TokenTypeAccess
+	// Admin acting as UserID; set only on impersonation tokens
+	ImpersonatorID *uuid.UUID `json:"imp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// tokenClaims are the claims of internal tokens (refresh, MFA) that only this service accepts
+type tokenClaims struct {
+	Type string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken generates a new access token
-func GenerateAccessToken(userID uuid.UUID, email, role string) (string, error) {
+// TokenIssuer returns the "iss" claim of every token (JWT_ISSUER)
+func TokenIssuer() string {
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "go-next-template"
+}
+
+// TokenAudience returns the "aud" claim of access tokens (JWT_AUDIENCE)
+// Refresh and MFA tokens use the issuer as audience: only this service accepts them
+func TokenAudience() string {
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		return audience
+	}
+	return TokenIssuer() + "/api"
+}
+
+// GenerateAccessToken generates a new access token for the given claims
+// Expiry, issue time, issuer, audience and type are always set here
+func GenerateAccessToken(claims Claims) (string, error) {
 	expiryTime := time.Now().Add(15 * time.Minute) // 15 minutes
 	if expiry := os.Getenv("JWT_ACCESS_EXPIRY"); expiry != "" {
 		if duration, err := time.ParseDuration(expiry); err == nil {
@@ -26,66 +63,67 @@ func GenerateAccessToken(userID uuid.UUID, email, role string) (string, error) {
 		}
 	}
 
-	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiryTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	return signAccessToken(claims, expiryTime)
+}
+
+func signAccessToken(claims Claims, expiresAt time.Time) (string, error) {
+	claims.Type = TokenTypeAccess
+	claims.Issuer = TokenIssuer()
+	claims.Audience = jwt.ClaimStrings{TokenAudience()}
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	claims.IssuedAt = jwt.NewNumericDate(time.Now())
+
+	return signToken(claims)
+}
+
+// GenerateImpersonationToken generates an access token that cannot outlive expiresAt
+// Impersonation tokens are never refreshed
+func GenerateImpersonationToken(claims Claims, expiresAt time.Time) (string, error) {
+	if claims.ImpersonatorID == nil {
+		return "", errors.New("impersonation token requires an impersonator")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(getJWTSecret()))
+	return signAccessToken(claims, expiresAt)
 }
 
-// GenerateRefreshToken generates a new refresh token
-func GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	expiryTime := time.Now().Add(7 * 24 * time.Hour) // 7 days
+// RefreshTokenTTL returns the refresh token lifetime
+func RefreshTokenTTL() time.Duration {
 	if expiry := os.Getenv("JWT_REFRESH_EXPIRY"); expiry != "" {
 		if duration, err := time.ParseDuration(expiry); err == nil {
-			expiryTime = time.Now().Add(duration)
+			return duration
 		}
 	}
-
-	claims := jwt.RegisteredClaims{
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(expiryTime),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(getJWTSecret()))
+	return 7 * 24 * time.Hour // 7 days
 }
 
-// VerifyToken verifies a JWT token and returns claims
-func VerifyToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(getJWTSecret()), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
+// GenerateRefreshToken generates a new refresh token
+func GenerateRefreshToken(userID uuid.UUID) (string, error) {
+	expiryTime := time.Now().Add(RefreshTokenTTL())
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
-	}
+	return signInternalToken(TokenTypeRefresh, userID, expiryTime)
+}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok {
-		return nil, errors.New("invalid token claims")
+// signInternalToken signs a refresh or MFA token
+func signInternalToken(tokenType string, userID uuid.UUID, expiresAt time.Time) (string, error) {
+	claims := tokenClaims{
+		Type: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // Keeps tokens unique when rotated within the same second
+			Issuer:    TokenIssuer(),
+			Audience:  jwt.ClaimStrings{TokenIssuer()},
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
 
-	return claims, nil
+	return signToken(claims)
 }
 
-// VerifyRefreshToken verifies a refresh token and returns user ID
-func VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(getJWTSecret()), nil
-	})
+// verifyInternalToken verifies a refresh or MFA token of the given type and returns user ID
+func verifyInternalToken(tokenString, tokenType string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, verificationKey,
+		jwtParserOptions(jwt.WithIssuer(TokenIssuer()), jwt.WithAudience(TokenIssuer()))...)
 
 	if err != nil {
 		return uuid.Nil, err
@@ -95,8 +133,8 @@ func VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
 		return uuid.Nil, errors.New("invalid token")
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok {
+	claims, ok := token.Claims.(*tokenClaims)
+	if !ok || claims.Type != tokenType {
 		return uuid.Nil, errors.New("invalid token claims")
 	}
 
@@ -108,10 +146,38 @@ func VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
 	return userID, nil
 }
 
-func getJWTSecret() string {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "your-super-secret-key-change-this-in-production"
+// VerifyToken verifies an access token and returns claims
+func VerifyToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, verificationKey,
+		jwtParserOptions(jwt.WithIssuer(TokenIssuer()), jwt.WithAudience(TokenAudience()))...)
+
+	if err != nil {
+		return nil, err
 	}
-	return secret
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || claims.Type != TokenTypeAccess || claims.UserID == uuid.Nil {
+		return nil, errors.New("invalid token claims")
+	}
+
+	return claims, nil
+}
+
+// VerifyRefreshToken verifies a refresh token and returns user ID
+func VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
+	return verifyInternalToken(tokenString, TokenTypeRefresh)
+}
+
+// GenerateMFAToken generates a short-lived token proving the password step of a login succeeded
+func GenerateMFAToken(userID uuid.UUID) (string, error) {
+	return signInternalToken(TokenTypeMFA, userID, time.Now().Add(5*time.Minute))
+}
+
+// VerifyMFAToken verifies an MFA challenge token and returns user ID
+func VerifyMFAToken(tokenString string) (uuid.UUID, error) {
+	return verifyInternalToken(tokenString, TokenTypeMFA)
 }
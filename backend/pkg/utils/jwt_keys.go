@@ -0,0 +1,258 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one key of the JWT key set
+// Private is nil for retired keys that are only kept to verify tokens issued before a rotation
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private interface{}
+	Public  interface{}
+}
+
+// keySet holds the key used to sign new tokens and every key accepted for verification
+type keySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var (
+	jwtKeys     *keySet
+	jwtKeysErr  error
+	jwtKeysOnce sync.Once
+)
+
+// InitJWTKeys loads the signing keys from JWT_KEYS_DIR
+//
+// The directory holds one PEM file per key, named after its key ID: "<kid>.pem" for a PKCS#8 (or
+// PKCS#1 RSA) private key, "<kid>.pub.pem" for a public key that only verifies. JWT_ACTIVE_KID picks
+// the signing key (default: the last private key by name). RSA keys sign with RS256, Ed25519 keys
+// with EdDSA. Without JWT_KEYS_DIR an ephemeral Ed25519 key is generated, so tokens don't survive
+// a restart and aren't shared between instances; that is refused when ENV=production.
+func InitJWTKeys() error {
+	jwtKeysOnce.Do(func() {
+		dir := os.Getenv("JWT_KEYS_DIR")
+		if dir == "" && os.Getenv("ENV") == "production" {
+			jwtKeysErr = errors.New("JWT_KEYS_DIR is required in production (JWT_SECRET is no longer used; see \"JWT Signing Keys\" in the README)")
+			return
+		}
+		jwtKeys, jwtKeysErr = loadKeySet(dir, os.Getenv("JWT_ACTIVE_KID"))
+	})
+	return jwtKeysErr
+}
+
+func loadKeySet(dir, activeKID string) (*keySet, error) {
+	if dir == "" {
+		key, err := ephemeralKey()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("JWT_KEYS_DIR is not set: signing tokens with ephemeral key %s (tokens are invalidated on restart)", key.ID)
+		return &keySet{active: key, keys: map[string]*signingKey{key.ID: key}}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	set := &keySet{keys: make(map[string]*signingKey)}
+	var lastPrivate string
+	for _, file := range files {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".pem"), ".pub")
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", file, err)
+		}
+
+		// A private key also provides the public half; never let a .pub.pem replace it
+		if existing, ok := set.keys[kid]; ok && existing.Private != nil {
+			continue
+		}
+		set.keys[kid] = key
+		if key.Private != nil {
+			lastPrivate = kid
+		}
+	}
+
+	if activeKID == "" {
+		activeKID = lastPrivate
+	}
+
+	active, ok := set.keys[activeKID]
+	if !ok || active.Private == nil {
+		return nil, fmt.Errorf("no private JWT key %q found in %s", activeKID, dir)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+func parseSigningKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, Private: k, Public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, Public: k}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, Private: k, Public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, Public: k}, nil
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+}
+
+func ephemeralKey() (*signingKey, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &signingKey{
+		ID:      "ephemeral-" + base64.RawURLEncoding.EncodeToString(id),
+		Method:  jwt.SigningMethodEdDSA,
+		Private: private,
+		Public:  public,
+	}, nil
+}
+
+// signToken signs claims with the active key and sets the "kid" header
+func signToken(claims jwt.Claims) (string, error) {
+	if err := InitJWTKeys(); err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwtKeys.active.Method, claims)
+	token.Header["kid"] = jwtKeys.active.ID
+	return token.SignedString(jwtKeys.active.Private)
+}
+
+// verificationKey selects the public key named by the token's "kid" header
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	if err := InitJWTKeys(); err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := jwtKeys.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	// The algorithm is fixed by the key, never by the token
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.Public, nil
+}
+
+// jwtParserOptions restricts parsing to the asymmetric algorithms we sign with
+func jwtParserOptions(options ...jwt.ParserOption) []jwt.ParserOption {
+	return append(options, jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS returns every verification key, for publishing at /.well-known/jwks.json
+func JWKS() ([]JWK, error) {
+	if err := InitJWTKeys(); err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(jwtKeys.keys))
+	for kid := range jwtKeys.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		key := jwtKeys.keys[kid]
+		jwk := JWK{KeyID: kid, Algorithm: key.Method.Alg(), Use: "sig"}
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		jwks = append(jwks, jwk)
+	}
+
+	return jwks, nil
+}
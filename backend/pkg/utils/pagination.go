@@ -0,0 +1,21 @@
+package utils
+
+import "github.com/gofiber/fiber/v2"
+
+// GetPagination reads page and limit query parameters (defaults: page 1, limit 20, max limit 100)
+func GetPagination(c *fiber.Ctx) (page, limit, offset int) {
+	page = c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+
+	limit = c.QueryInt("limit", 20)
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return page, limit, (page - 1) * limit
+}
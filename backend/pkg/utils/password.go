@@ -1,15 +1,276 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
 
-// HashPassword hashes a password using bcrypt
+	"github.com/your-org/go-next-template/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes and verifies passwords in one storage format
+type PasswordHasher interface {
+	// Hash returns an encoded hash of the password including its salt and parameters
+	Hash(password string) (string, error)
+	// Verify compares a password with a hash produced by this hasher
+	Verify(password, hash string) (bool, error)
+	// Recognizes reports whether the hash is in this hasher's format
+	Recognizes(hash string) bool
+	// NeedsRehash reports whether a hash in this format was made with outdated parameters
+	NeedsRehash(hash string) bool
+}
+
+var (
+	passwordHasher     PasswordHasher
+	passwordHasherOnce sync.Once
+
+	// knownHashers verify existing hashes whatever the current default is
+	knownHashers = []PasswordHasher{&Argon2idHasher{}, &BcryptHasher{}}
+
+	errUnknownHashFormat   = errors.New("unknown password hash format")
+	errInvalidArgon2Params = errors.New("invalid argon2 parameters")
+)
+
+// SetPasswordHasher replaces the hasher used for new hashes
+func SetPasswordHasher(hasher PasswordHasher) {
+	passwordHasherOnce.Do(func() {})
+	passwordHasher = hasher
+}
+
+// defaultPasswordHasher returns the hasher selected by PASSWORD_HASHER ("argon2id" or "bcrypt")
+func defaultPasswordHasher() PasswordHasher {
+	passwordHasherOnce.Do(func() {
+		switch os.Getenv("PASSWORD_HASHER") {
+		case "bcrypt":
+			passwordHasher = NewBcryptHasher()
+		default:
+			passwordHasher = NewArgon2idHasher()
+		}
+	})
+	return passwordHasher
+}
+
+// HashPassword hashes a password with the default hasher (argon2id unless configured otherwise)
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return defaultPasswordHasher().Hash(password)
 }
 
-// CheckPasswordHash compares a password with a hash
+// CheckPasswordHash compares a password with a hash in any supported format
 func CheckPasswordHash(password, hash string) bool {
+	hasher := hasherFor(hash)
+	if hasher == nil {
+		return false
+	}
+
+	ok, err := hasher.Verify(password, hash)
+	return err == nil && ok
+}
+
+// PasswordNeedsRehash reports whether a hash should be replaced after the next successful login,
+// because it uses another algorithm than the default or outdated parameters
+func PasswordNeedsRehash(hash string) bool {
+	current := defaultPasswordHasher()
+	if !current.Recognizes(hash) {
+		return true
+	}
+	return current.NeedsRehash(hash)
+}
+
+func hasherFor(hash string) PasswordHasher {
+	if current := defaultPasswordHasher(); current.Recognizes(hash) {
+		return current
+	}
+	for _, hasher := range knownHashers {
+		if hasher.Recognizes(hash) {
+			return hasher
+		}
+	}
+	return nil
+}
+
+// Bounds for argon2id parameters, from the environment or from stored hashes. The upper
+// bounds stop a misconfiguration or a tampered hash from forcing huge work per verification.
+const (
+	argon2MinMemory      = 8
+	argon2MaxMemory      = 4 * 1024 * 1024 // KiB (4 GiB)
+	argon2MaxIterations  = 64
+	argon2MaxParallelism = 64
+	argon2MinSaltLength  = 8
+	argon2MinKeyLength   = 16
+	argon2MaxLength      = 1024 // Salt and key, in bytes
+)
+
+// Argon2idHasher hashes passwords with argon2id and encodes them in PHC string format:
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>
+type Argon2idHasher struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewArgon2idHasher returns an argon2id hasher configured from ARGON2_* environment variables
+// Defaults follow the OWASP recommendation (19 MiB, 2 iterations, 1 lane); out-of-range values fall back to them
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      uint32(hashParam("ARGON2_MEMORY", 19456, argon2MinMemory, argon2MaxMemory)),
+		Iterations:  uint32(hashParam("ARGON2_ITERATIONS", 2, 1, argon2MaxIterations)),
+		Parallelism: uint8(hashParam("ARGON2_PARALLELISM", 1, 1, argon2MaxParallelism)),
+		SaltLength:  uint32(hashParam("ARGON2_SALT_LENGTH", 16, argon2MinSaltLength, argon2MaxLength)),
+		KeyLength:   uint32(hashParam("ARGON2_KEY_LENGTH", 32, argon2MinKeyLength, argon2MaxLength)),
+	}
+}
+
+// hashParam reads a hashing parameter, falling back to the default when it is out of range
+func hashParam(key string, defaultValue, min, max int) int {
+	value := config.GetEnvInt(key, defaultValue)
+	if value < min || value > max {
+		log.Printf("%s=%d is out of range [%d, %d]: using %d", key, value, min, max, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// validate rejects parameters argon2 panics on (no lanes), that would make any password
+// verify (an empty key) or that exceed the work a single hash may take
+func (h *Argon2idHasher) validate() error {
+	if h.Memory < argon2MinMemory || h.Memory > argon2MaxMemory ||
+		h.Iterations < 1 || h.Iterations > argon2MaxIterations ||
+		h.Parallelism < 1 || h.Parallelism > argon2MaxParallelism ||
+		h.SaltLength < 1 || h.SaltLength > argon2MaxLength ||
+		h.KeyLength < 1 || h.KeyLength > argon2MaxLength {
+		return errInvalidArgon2Params
+	}
+	return nil
+}
+
+// Hash implements PasswordHasher
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	if err := h.validate(); err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify implements PasswordHasher
+func (h *Argon2idHasher) Verify(password, hash string) (bool, error) {
+	params, salt, key, err := decodeArgon2idHash(hash)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+// Recognizes implements PasswordHasher
+func (h *Argon2idHasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// NeedsRehash implements PasswordHasher
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := decodeArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != h.Memory ||
+		params.Iterations != h.Iterations ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength ||
+		uint32(len(key)) != h.KeyLength
+}
+
+func decodeArgon2idHash(hash string) (*Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, errInvalidArgon2Params
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	if err := params.validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher hashes passwords with bcrypt (kept to verify and upgrade existing hashes)
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher with BCRYPT_COST (default bcrypt.DefaultCost)
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{Cost: hashParam("BCRYPT_COST", bcrypt.DefaultCost, bcrypt.MinCost, bcrypt.MaxCost)}
+}
+
+// Hash implements PasswordHasher
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(bytes), err
+}
+
+// Verify implements PasswordHasher
+func (h *BcryptHasher) Verify(password, hash string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Recognizes implements PasswordHasher
+func (h *BcryptHasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash implements PasswordHasher
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
 }
@@ -18,6 +18,15 @@ func ErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
 	})
 }
 
+// ValidationErrorResponse sends an error JSON response with structured details (e.g. field or rule violations)
+func ValidationErrorResponse(c *fiber.Ctx, message string, details interface{}) error {
+	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+		"success": false,
+		"error":   message,
+		"details": details,
+	})
+}
+
 // PaginatedResponse sends a paginated JSON response
 func PaginatedResponse(c *fiber.Ctx, data interface{}, page, limit, total int) error {
 	return c.JSON(fiber.Map{
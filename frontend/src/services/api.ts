@@ -36,11 +36,14 @@ api.interceptors.response.use(
           throw new Error('No refresh token');
         }
 
-        const { data } = await axios.post(`${api.defaults.baseURL}/auth/refresh`, {
+        const { data: body } = await axios.post(`${api.defaults.baseURL}/auth/refresh`, {
           refresh_token: refreshToken,
         });
+        const data = body.data;
 
+        // Refresh tokens are rotated on every use, so store the new one as well
         localStorage.setItem('access_token', data.access_token);
+        localStorage.setItem('refresh_token', data.refresh_token);
 
         // Retry original request
         if (originalRequest.headers) {